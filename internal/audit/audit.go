@@ -0,0 +1,190 @@
+// Package audit records every call to the server as a JSON line: who called (peer address
+// and API key ID), what was asked for, and how it ended
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/peercred"
+	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Events recorded as the message of each record
+const (
+	EventRPC         = "rpc"
+	EventAuthFailure = "auth_failure"
+)
+
+// Logger writes one record per call
+type Logger struct {
+	log *slog.Logger
+}
+
+// New returns a Logger writing JSON lines to w
+func New(w io.Writer) *Logger {
+	return &Logger{log: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// call collects what the interceptors learn about a call while it runs
+type call struct {
+	authFailure auth.FailureReason
+}
+
+type callKey struct{}
+
+// AuthFailure marks the call as rejected by authentication, so that it is recorded as an
+// auth_failure event with the reason; use it with auth.WithFailureHook
+func (l *Logger) AuthFailure(ctx context.Context, fullMethod string, reason auth.FailureReason) {
+	if c, ok := ctx.Value(callKey{}).(*call); ok {
+		c.authFailure = reason
+	}
+}
+
+// UnaryServerInterceptor records every unary call except health checks. Place it first in the chain so that
+// calls rejected by later interceptors (auth, rate limits) are recorded too.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, auth.HealthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		c := &call{}
+
+		resp, err := handler(context.WithValue(ctx, callKey{}, c), req)
+
+		l.record(ctx, info.FullMethod, runtimeFilter(req), c, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records every stream except health watches when it ends
+func (l *Logger) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if strings.HasPrefix(info.FullMethod, auth.HealthServicePrefix) {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		c := &call{}
+		stream := &auditedStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), callKey{}, c)}
+
+		err := handler(srv, stream)
+
+		l.record(ss.Context(), info.FullMethod, stream.filter, c, err, time.Since(start))
+		return err
+	}
+}
+
+func (l *Logger) record(ctx context.Context, fullMethod string, filter []string, c *call, err error, duration time.Duration) {
+	event, level := EventRPC, slog.LevelInfo
+	if c.authFailure != "" {
+		event, level = EventAuthFailure, slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{slog.String("peer", peerAddress(ctx))}
+	if cred, ok := peercred.FromContext(ctx); ok {
+		attrs = append(attrs, slog.Int("uid", cred.UID))
+	}
+	// 인증 전에 거부된 호출의 키는 클라이언트가 주장한 것일 뿐이라 따로 기록함
+	keyAttr := "key"
+	if !keyVerified(c.authFailure) {
+		keyAttr = "claimed_key"
+	}
+	attrs = append(attrs,
+		slog.String(keyAttr, keyID(ctx)),
+		slog.String("method", fullMethod),
+	)
+	if len(filter) > 0 {
+		attrs = append(attrs, slog.Any("runtime_filter", filter))
+	}
+
+	st := status.Convert(err)
+	attrs = append(attrs, slog.String("code", st.Code().String()))
+	if c.authFailure != "" {
+		attrs = append(attrs, slog.String("reason", string(c.authFailure)))
+	}
+	if err != nil && st.Message() != "" {
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+	attrs = append(attrs, slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)))
+
+	l.log.LogAttrs(context.Background(), level, event, attrs...)
+}
+
+// keyID identifies the API key of a call without revealing it: the auth.KeyID of a plain
+// key, the same ID a signed call carries. Keys short enough to be guessed from their ID
+// are hidden entirely.
+func keyID(ctx context.Context) string {
+	key, err := auth.ExtractAPIKey(ctx)
+	if err != nil || key == "" {
+		id, _ := auth.ExtractKeyID(ctx)
+		return id
+	}
+
+	if auth.MaskKey(key) == key {
+		return "***"
+	}
+	return auth.KeyID(key)
+}
+
+// keyVerified reports whether the key of a call was checked before it ended: the call
+// succeeded or was only rejected by the key's scopes
+func keyVerified(reason auth.FailureReason) bool {
+	return reason == "" || reason == auth.ReasonInvalidScopes || reason == auth.ReasonScopeDenied
+}
+
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func runtimeFilter(req interface{}) []string {
+	switch req := req.(type) {
+	case *proto.ObserveRequest:
+		return req.RuntimeFilter
+	case *proto.WatchRequest:
+		return req.RuntimeFilter
+	}
+	return nil
+}
+
+// auditedStream passes the call to later interceptors and remembers the runtime filter
+// of the request
+type auditedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	filter []string
+}
+
+func (s *auditedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *auditedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.filter == nil {
+		s.filter = runtimeFilter(m)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecordKey(t *testing.T) {
+	const key = "watcher_0123456789abcdefghijklmnopqrstuv"
+	plain := metadata.Pairs(auth.APIKeyHeader, key)
+	signed := metadata.Pairs(auth.KeyIDHeader, auth.KeyID(key))
+
+	tests := []struct {
+		name      string
+		md        metadata.MD
+		reason    auth.FailureReason
+		wantField string
+		wantValue string
+	}{
+		{name: "plain key", md: plain, wantField: "key", wantValue: auth.KeyID(key)},
+		{name: "signed call", md: signed, wantField: "key", wantValue: auth.KeyID(key)},
+		{name: "short key", md: metadata.Pairs(auth.APIKeyHeader, "secret"), wantField: "key", wantValue: "***"},
+		{name: "scope denied", md: plain, reason: auth.ReasonScopeDenied, wantField: "key", wantValue: auth.KeyID(key)},
+		{name: "invalid key", md: plain, reason: auth.ReasonInvalidKey, wantField: "claimed_key", wantValue: auth.KeyID(key)},
+		{name: "invalid signature", md: signed, reason: auth.ReasonInvalidSignature, wantField: "claimed_key", wantValue: auth.KeyID(key)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf)
+
+			info := &grpc.UnaryServerInfo{FullMethod: "/watcher.WatcherService/ObserveRuntimes"}
+			l.UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), tt.md), nil, info,
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					if tt.reason == "" {
+						return nil, nil
+					}
+					l.AuthFailure(ctx, info.FullMethod, tt.reason)
+					return nil, status.Error(codes.PermissionDenied, "rejected")
+				})
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("invalid record %q: %v", buf.String(), err)
+			}
+			if got := record[tt.wantField]; got != tt.wantValue {
+				t.Errorf("%s = %v, want %q in %s", tt.wantField, got, tt.wantValue, buf.String())
+			}
+			for _, field := range []string{"key", "claimed_key"} {
+				if _, ok := record[field]; ok && field != tt.wantField {
+					t.Errorf("record also has %s: %s", field, buf.String())
+				}
+			}
+		})
+	}
+}
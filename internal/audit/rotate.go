@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// rotateRetryInterval is how long writes go on to the current file after a failed
+// rotation before rotating is tried again
+const rotateRetryInterval = time.Minute
+
+// RotatingFile is an append-only log file that is renamed to path.1 (path.1 to path.2,
+// and so on) once it would grow beyond MaxSize bytes. Only MaxBackups old files are kept.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	closed  bool
+	retryAt time.Time
+	onError []func(error)
+}
+
+// OpenRotatingFile opens path for appending. A maxSize of 0 disables rotation.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// OnError calls hook when rotating or reopening the file fails. The audit logger drops
+// write errors, so this is the only way to learn that records are not being rotated or
+// written.
+func (f *RotatingFile) OnError(hook func(error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onError = append(f.onError, hook)
+}
+
+// Write appends p, rotating first if p would not fit. Each audit record is written
+// with a single call, so records never span two files. If rotation fails, records keep
+// going to path and rotation is retried after rotateRetryInterval.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize && time.Now().After(f.retryAt) {
+		if err := f.rotate(); err != nil {
+			f.retryAt = time.Now().Add(rotateRetryInterval)
+			f.failed(err)
+		}
+	}
+
+	// 회전이나 이전 재오픈이 실패했으면 원래 경로를 다시 열어 기록을 이어감
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			f.failed(err)
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) failed(err error) {
+	for _, hook := range f.onError {
+		hook(err)
+	}
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and closes the file so that
+// the next write starts a new one
+func (f *RotatingFile) rotate() error {
+	// 닫기에 실패해도 파일은 해제되므로, 이후에는 원래 경로를 다시 열어 씀
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	if f.maxBackups > 0 {
+		os.Remove(backupName(f.path, f.maxBackups))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			os.Rename(backupName(f.path, i), backupName(f.path, i+1))
+		}
+		if err := os.Rename(f.path, backupName(f.path, 1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the current file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, record := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(record)); err != nil {
+			t.Fatalf("Write(%q) error = %v", record, err)
+		}
+	}
+
+	// 가장 오래된 기록은 백업 개수를 넘어 삭제됨
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		if got := readFile(t, name); got != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists beyond max backups", filepath.Base(path))
+	}
+}
+
+func TestRotatingFileRotateFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	// 백업 이름에 비어 있지 않은 디렉터리가 있으면 이름 변경이 실패함
+	if err := os.MkdirAll(filepath.Join(path+".1", "keep"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var errs []error
+	f.OnError(func(err error) { errs = append(errs, err) })
+
+	for _, record := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := f.Write([]byte(record)); err != nil {
+			t.Fatalf("Write(%q) error = %v, want the record kept in the original file", record, err)
+		}
+	}
+
+	if len(errs) != 1 {
+		t.Errorf("OnError called %d times, want once until the retry interval passes", len(errs))
+	}
+	if got, want := readFile(t, path), "first\nsecond\nthird\n"; got != want {
+		t.Errorf("audit.log = %q, want %q", got, want)
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := OpenRotatingFile(filepath.Join(t.TempDir(), "audit.log"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := f.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Errorf("Write() after Close error = %v, want os.ErrClosed", err)
+	}
+}
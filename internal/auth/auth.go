@@ -16,8 +16,13 @@ type Validator interface {
 	Validate(key string) bool
 }
 
-// ExtractAPIKey extracts API key from gRPC metadata
+// ExtractAPIKey extracts API key from gRPC metadata. For signed calls it returns the key
+// that signed the call once the auth interceptor has verified the signature.
 func ExtractAPIKey(ctx context.Context) (string, error) {
+	if key, ok := ctx.Value(verifiedKey{}).(string); ok {
+		return key, nil
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return "", fmt.Errorf("no metadata in context")
@@ -31,7 +36,15 @@ func ExtractAPIKey(ctx context.Context) (string, error) {
 	return values[0], nil
 }
 
-// InjectAPIKey adds API key to outgoing gRPC metadata
+// ValidatedKey returns the API key the auth interceptor validated for the call. It
+// reports false when auth is disabled, for trusted peers and for health checks.
+func ValidatedKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(verifiedKey{}).(string)
+	return key, ok
+}
+
+// InjectAPIKey adds API key to outgoing gRPC metadata. On connections using
+// SigningUnaryClientInterceptor the key is replaced by a signature before it is sent.
 func InjectAPIKey(ctx context.Context, apiKey string) context.Context {
 	md, ok := metadata.FromOutgoingContext(ctx)
 	if !ok {
@@ -44,3 +57,11 @@ func InjectAPIKey(ctx context.Context, apiKey string) context.Context {
 
 	return metadata.NewOutgoingContext(ctx, md)
 }
+
+// MaskKey shortens an API key for display, keeping enough to tell keys apart
+func MaskKey(key string) string {
+	if len(key) <= 14 {
+		return key
+	}
+	return key[:10] + "..." + key[len(key)-4:]
+}
@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/peercred"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Reasons passed to failure hooks by a Guard
+const (
+	ReasonLockedOut     FailureReason = "locked_out"
+	ReasonDeniedAddress FailureReason = "denied_address"
+)
+
+// GuardConfig configures brute-force protection
+type GuardConfig struct {
+	// MaxFailures invalid API keys from one client lock it out; 0 disables lockouts
+	MaxFailures int
+	// Lockout is the first lockout; every further failure doubles it up to MaxLockout
+	Lockout    time.Duration
+	MaxLockout time.Duration
+
+	// Exempt lists networks that are never locked out (e.g. monitoring hosts). They still
+	// need a valid API key.
+	Exempt []string
+	// Deny lists networks that are always rejected, health checks included
+	Deny []string
+}
+
+// LockoutHook is called when a client is locked out
+type LockoutHook func(client string, failures int, lockout time.Duration)
+
+// Guard tracks invalid API keys per client and locks out clients that keep guessing.
+// IPv6 clients are tracked per /64, the smallest network usually handed to one host.
+// Failures are forgotten only with time, not when the client later presents a valid
+// key, so a client holding one key can't use it to keep guessing others. Keys are still
+// compared in constant time by the Validator; the guard only decides whether a client
+// may try at all.
+type Guard struct {
+	cfg    GuardConfig
+	exempt []netip.Prefix
+	deny   []netip.Prefix
+
+	onLockout []LockoutHook
+
+	mu        sync.Mutex
+	clients   map[string]*failures
+	lastSweep time.Time
+}
+
+type failures struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// NewGuard returns a Guard for cfg
+func NewGuard(cfg GuardConfig) (*Guard, error) {
+	exempt, err := parsePrefixes(cfg.Exempt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lockout exempt list: %w", err)
+	}
+	deny, err := parsePrefixes(cfg.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny list: %w", err)
+	}
+
+	if cfg.MaxLockout < cfg.Lockout {
+		cfg.MaxLockout = cfg.Lockout
+	}
+
+	return &Guard{
+		cfg:     cfg,
+		exempt:  exempt,
+		deny:    deny,
+		clients: make(map[string]*failures),
+	}, nil
+}
+
+// OnLockout calls hook whenever a client is locked out, e.g. to log it
+func (g *Guard) OnLockout(hook LockoutHook) {
+	g.onLockout = append(g.onLockout, hook)
+}
+
+// WithGuard rejects calls from denied networks and from clients locked out after
+// repeated invalid API keys
+func WithGuard(g *Guard) Option {
+	return func(o *options) {
+		o.guard = g
+	}
+}
+
+// denied rejects the call if its client address is on the deny list
+func (g *Guard) denied(ctx context.Context) error {
+	if addr, ok := peerAddr(ctx); ok && containsAddr(g.deny, addr) {
+		return status.Error(codes.PermissionDenied, "client address is not allowed")
+	}
+	return nil
+}
+
+// check rejects the call if its client is locked out. Denied addresses are rejected
+// earlier by denied, before health checks are let through.
+func (g *Guard) check(ctx context.Context, now time.Time) (FailureReason, error) {
+	if g.cfg.MaxFailures <= 0 || g.exempted(ctx) {
+		return "", nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f := g.clients[peercred.ClientID(ctx)]
+	if f == nil || !now.Before(f.lockedUntil) {
+		return "", nil
+	}
+
+	return ReasonLockedOut, lockedOut(f.lockedUntil.Sub(now))
+}
+
+// failed records an invalid API key and locks the client out once it reaches MaxFailures
+func (g *Guard) failed(ctx context.Context, now time.Time) {
+	if g.cfg.MaxFailures <= 0 {
+		return
+	}
+	if g.exempted(ctx) {
+		return
+	}
+
+	client := peercred.ClientID(ctx)
+
+	g.mu.Lock()
+	if now.Sub(g.lastSweep) > g.forgetAfter() {
+		g.sweep(now)
+	}
+
+	f := g.clients[client]
+	if f == nil {
+		f = &failures{}
+		g.clients[client] = f
+	}
+	f.count++
+	f.last = now
+
+	var lockout time.Duration
+	if f.count >= g.cfg.MaxFailures {
+		lockout = g.lockout(f.count)
+		f.lockedUntil = now.Add(lockout)
+	}
+	count := f.count
+	g.mu.Unlock()
+
+	if lockout > 0 {
+		for _, hook := range g.onLockout {
+			hook(client, count, lockout)
+		}
+	}
+}
+
+func (g *Guard) exempted(ctx context.Context) bool {
+	addr, ok := peerAddr(ctx)
+	return ok && containsAddr(g.exempt, addr)
+}
+
+// lockout doubles the base lockout for every failure beyond MaxFailures
+func (g *Guard) lockout(count int) time.Duration {
+	exponent := float64(count - g.cfg.MaxFailures)
+	lockout := float64(g.cfg.Lockout) * math.Pow(2, exponent)
+	if lockout > float64(g.cfg.MaxLockout) {
+		return g.cfg.MaxLockout
+	}
+	return time.Duration(lockout)
+}
+
+// forgetAfter is how long failures are remembered after the last one
+func (g *Guard) forgetAfter() time.Duration {
+	return max(g.cfg.MaxLockout, 15*time.Minute)
+}
+
+// sweep drops clients whose last failure is long past
+func (g *Guard) sweep(now time.Time) {
+	for client, f := range g.clients {
+		if now.Sub(f.last) > g.forgetAfter() && !now.Before(f.lockedUntil) {
+			delete(g.clients, client)
+		}
+	}
+	g.lastSweep = now
+}
+
+// lockedOut returns the error for a locked out client, with a RetryInfo detail
+func lockedOut(remaining time.Duration) error {
+	seconds := int64(math.Ceil(remaining.Seconds()))
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("too many invalid API keys, locked out for %ds", seconds))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(remaining)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func peerAddr(ctx context.Context) (netip.Addr, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return netip.Addr{}, false
+	}
+
+	host := p.Addr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefixes accepts CIDRs (10.0.0.0/8) and single addresses (192.0.2.1)
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
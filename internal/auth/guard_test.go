@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// peerContext returns a context for a call from addr
+func peerContext(addr string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: net.TCPAddrFromAddrPort(netip.MustParseAddrPort(addr))})
+}
+
+func TestGuardLockout(t *testing.T) {
+	cfg := GuardConfig{MaxFailures: 3, Lockout: time.Minute, MaxLockout: 3 * time.Minute}
+
+	tests := []struct {
+		name        string
+		failures    int
+		after       time.Duration // since the last failure
+		wantLocked  bool
+		wantLockout time.Duration
+	}{
+		{name: "below the limit", failures: 2},
+		{name: "locked at the limit", failures: 3, wantLocked: true, wantLockout: time.Minute},
+		{name: "lockout doubles", failures: 4, wantLocked: true, wantLockout: 2 * time.Minute},
+		{name: "lockout is capped", failures: 6, wantLocked: true, wantLockout: 3 * time.Minute},
+		{name: "lockout expires", failures: 3, after: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGuard(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lockout time.Duration
+			g.OnLockout(func(client string, failures int, d time.Duration) { lockout = d })
+
+			ctx := peerContext("192.0.2.10:40000")
+			now := time.Unix(1700000000, 0)
+			for i := 0; i < tt.failures; i++ {
+				g.failed(ctx, now)
+			}
+
+			reason, err := g.check(ctx, now.Add(tt.after))
+			if locked := err != nil; locked != tt.wantLocked {
+				t.Fatalf("check() = %q, %v, want locked %v", reason, err, tt.wantLocked)
+			}
+			if tt.wantLocked {
+				if reason != ReasonLockedOut || status.Code(err) != codes.ResourceExhausted {
+					t.Errorf("check() = %q, %v, want a locked_out ResourceExhausted error", reason, err)
+				}
+				if lockout != tt.wantLockout {
+					t.Errorf("lockout = %s, want %s", lockout, tt.wantLockout)
+				}
+			}
+		})
+	}
+}
+
+func TestGuardClients(t *testing.T) {
+	cfg := GuardConfig{
+		MaxFailures: 1,
+		Lockout:     time.Minute,
+		Exempt:      []string{"10.1.0.0/24"},
+		Deny:        []string{"203.0.113.0/24", "2001:db8:bad::/48"},
+	}
+
+	tests := []struct {
+		name       string
+		failing    string // client sending an invalid key
+		checked    string // client checked afterwards
+		wantReason FailureReason
+	}{
+		{name: "same client", failing: "192.0.2.10:40000", checked: "192.0.2.10:40001", wantReason: ReasonLockedOut},
+		{name: "other IPv4 client", failing: "192.0.2.10:40000", checked: "192.0.2.11:40000"},
+		{name: "exempt network", failing: "10.1.0.5:40000", checked: "10.1.0.5:40000"},
+		{name: "IPv4-mapped address is exempt", failing: "[::ffff:10.1.0.5]:40000", checked: "10.1.0.5:40000"},
+		{name: "IPv4-mapped address is the same client", failing: "[::ffff:192.0.2.10]:40000", checked: "192.0.2.10:40000", wantReason: ReasonLockedOut},
+		{name: "same IPv6 /64", failing: "[2001:db8:1:2::1]:40000", checked: "[2001:db8:1:2:ffff::9]:40000", wantReason: ReasonLockedOut},
+		{name: "other IPv6 /64", failing: "[2001:db8:1:2::1]:40000", checked: "[2001:db8:1:3::1]:40000"},
+		{name: "denied IPv4", checked: "203.0.113.7:40000", wantReason: ReasonDeniedAddress},
+		{name: "denied IPv4-mapped", checked: "[::ffff:203.0.113.7]:40000", wantReason: ReasonDeniedAddress},
+		{name: "denied IPv6", checked: "[2001:db8:bad:1::1]:40000", wantReason: ReasonDeniedAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGuard(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			now := time.Unix(1700000000, 0)
+			if tt.failing != "" {
+				g.failed(peerContext(tt.failing), now)
+			}
+
+			// 인터셉터처럼 거부 목록을 먼저 확인
+			ctx := peerContext(tt.checked)
+			reason, err := ReasonDeniedAddress, g.denied(ctx)
+			if err == nil {
+				reason, err = g.check(ctx, now)
+			}
+			if reason != tt.wantReason || (err != nil) != (tt.wantReason != "") {
+				t.Errorf("check() = %q, %v, want %q", reason, err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestGuardDeniesHealthChecks(t *testing.T) {
+	g, err := NewGuard(GuardConfig{Deny: []string{"203.0.113.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &options{guard: g}
+
+	for addr, wantCode := range map[string]codes.Code{
+		"203.0.113.7:40000": codes.PermissionDenied,
+		"192.0.2.10:40000":  codes.OK,
+	} {
+		_, _, err := o.authorize(peerContext(addr), nil, HealthServicePrefix+"Check")
+		if got := status.Code(err); got != wantCode {
+			t.Errorf("health check from %s = %v, want %v", addr, got, wantCode)
+		}
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{values: []string{"10.0.0.0/8", " 192.0.2.1 "}, want: []string{"10.0.0.0/8", "192.0.2.1/32"}},
+		{values: []string{"10.1.2.3/16"}, want: []string{"10.1.0.0/16"}},
+		{values: []string{"2001:db8::1/64", "2001:db8::1"}, want: []string{"2001:db8::/64", "2001:db8::1/128"}},
+		{values: []string{"::ffff:10.0.0.0/104", "::ffff:192.0.2.1"}, want: []string{"10.0.0.0/8", "192.0.2.1/32"}},
+		{values: []string{"10.0.0.0/33"}, wantErr: true},
+		{values: []string{"example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		prefixes, err := parsePrefixes(tt.values)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePrefixes(%q) error = %v, want error %v", tt.values, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+
+		got := make([]string, len(prefixes))
+		for i, prefix := range prefixes {
+			got[i] = prefix.String()
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePrefixes(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testKey    = "watcher_0123456789abcdefghijklmnopqrstuv"
+	testMethod = "/watcher.WatcherService/ObserveRuntimes"
+)
+
+// keyResolver resolves the IDs of a fixed set of keys
+type keyResolver []string
+
+func (r keyResolver) ResolveKeyID(id string) (string, bool) {
+	for _, key := range r {
+		if KeyID(key) == id {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// signedContext returns the incoming context of a call to testMethod signed with key,
+// after edit has changed its metadata
+func signedContext(t *testing.T, key string, edit func(md metadata.MD)) context.Context {
+	t.Helper()
+	ctx, err := InjectSignature(context.Background(), key, testMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	if edit != nil {
+		edit(md)
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestSignatureVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		method     string // verified method; testMethod if empty
+		edit       func(md metadata.MD)
+		clock      time.Duration // server clock relative to the client's
+		wantReason FailureReason
+		wantCode   codes.Code
+	}{
+		{name: "valid", key: testKey},
+		{name: "within clock skew", key: testKey, clock: 4 * time.Minute},
+		{name: "server clock ahead", key: testKey, clock: 6 * time.Minute, wantReason: ReasonStaleSignature, wantCode: codes.Unauthenticated},
+		{name: "server clock behind", key: testKey, clock: -6 * time.Minute, wantReason: ReasonStaleSignature, wantCode: codes.Unauthenticated},
+		{name: "unknown key", key: "watcher_unknownunknownunknownunknown", wantReason: ReasonInvalidSignature, wantCode: codes.PermissionDenied},
+		{
+			name: "bad signature", key: testKey,
+			edit:       func(md metadata.MD) { md.Set(SignatureHeader, strings.Repeat("0", 64)) },
+			wantReason: ReasonInvalidSignature, wantCode: codes.PermissionDenied,
+		},
+		{name: "signed for another method", key: testKey, method: "/watcher.WatcherService/RotateKey", wantReason: ReasonInvalidSignature, wantCode: codes.PermissionDenied},
+		{
+			name: "tampered timestamp", key: testKey,
+			edit: func(md metadata.MD) {
+				seconds, _ := strconv.ParseInt(md.Get(TimestampHeader)[0], 10, 64)
+				md.Set(TimestampHeader, strconv.FormatInt(seconds-1, 10))
+			},
+			wantReason: ReasonInvalidSignature, wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "missing nonce", key: testKey,
+			edit:       func(md metadata.MD) { md.Delete(NonceHeader) },
+			wantReason: ReasonInvalidSignature, wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "nonce too long", key: testKey,
+			edit:       func(md metadata.MD) { md.Set(NonceHeader, strings.Repeat("a", maxNonceLength+1)) },
+			wantReason: ReasonInvalidSignature, wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "invalid timestamp", key: testKey,
+			edit:       func(md metadata.MD) { md.Set(TimestampHeader, "yesterday") },
+			wantReason: ReasonInvalidSignature, wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewSignatureVerifier(keyResolver{testKey}, 0)
+			method := tt.method
+			if method == "" {
+				method = testMethod
+			}
+
+			key, reason, err := v.verify(signedContext(t, tt.key, tt.edit), method, time.Now().Add(tt.clock))
+			if reason != tt.wantReason || status.Code(err) != tt.wantCode {
+				t.Fatalf("verify() = %q, %v, want %q with %v", reason, err, tt.wantReason, tt.wantCode)
+			}
+			if err == nil && key != testKey {
+				t.Errorf("verify() returned key %q, want the signing key", key)
+			}
+		})
+	}
+}
+
+func TestSignatureReplay(t *testing.T) {
+	v := NewSignatureVerifier(keyResolver{testKey}, time.Minute)
+	ctx := signedContext(t, testKey, nil)
+	now := time.Now()
+
+	if _, _, err := v.verify(ctx, testMethod, now); err != nil {
+		t.Fatalf("first verify() error = %v", err)
+	}
+	if _, reason, err := v.verify(ctx, testMethod, now.Add(time.Second)); reason != ReasonReplayedNonce || status.Code(err) != codes.PermissionDenied {
+		t.Errorf("replayed verify() = %q, %v, want %q", reason, err, ReasonReplayedNonce)
+	}
+
+	// 서명이 틀린 요청은 nonce를 소모하지 않음
+	forged := signedContext(t, testKey, func(md metadata.MD) { md.Set(SignatureHeader, strings.Repeat("0", 64)) })
+	if _, reason, _ := v.verify(forged, testMethod, now); reason != ReasonInvalidSignature {
+		t.Fatalf("forged verify() reason = %q, want %q", reason, ReasonInvalidSignature)
+	}
+	md, _ := metadata.FromIncomingContext(forged)
+	genuine := signedContext(t, testKey, func(m metadata.MD) {
+		m.Set(NonceHeader, md.Get(NonceHeader)[0])
+		m.Set(SignatureHeader, sign(testKey, testMethod, KeyID(testKey), m.Get(TimestampHeader)[0], md.Get(NonceHeader)[0]))
+	})
+	if _, _, err := v.verify(genuine, testMethod, now); err != nil {
+		t.Errorf("verify() after a forged request with the same nonce error = %v", err)
+	}
+
+	// 만료된 요청의 nonce는 다음 요청 때 정리됨
+	later := now.Add(3 * time.Minute)
+	fresh := signedContext(t, testKey, func(m metadata.MD) {
+		m.Set(TimestampHeader, strconv.FormatInt(later.Unix(), 10))
+		m.Set(SignatureHeader, sign(testKey, testMethod, KeyID(testKey), m.Get(TimestampHeader)[0], m.Get(NonceHeader)[0]))
+	})
+	if _, _, err := v.verify(fresh, testMethod, later); err != nil {
+		t.Fatalf("verify() of a later request error = %v", err)
+	}
+	v.mu.Lock()
+	remembered := len(v.nonces)
+	v.mu.Unlock()
+	if remembered != 1 {
+		t.Errorf("%d nonces remembered, want only the latest one", remembered)
+	}
+}
+
+func TestSigningInterceptorDropsKey(t *testing.T) {
+	ctx, err := signOutgoing(InjectAPIKey(context.Background(), testKey), testMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if keys := md.Get(APIKeyHeader); len(keys) != 0 {
+		t.Errorf("signed call still carries the API key: %q", keys)
+	}
+	if got := md.Get(KeyIDHeader); len(got) != 1 || got[0] != KeyID(testKey) {
+		t.Errorf("key ID = %q, want %q", got, KeyID(testKey))
+	}
+}
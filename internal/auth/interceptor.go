@@ -2,27 +2,82 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// FailureReason explains why a request was rejected
+type FailureReason string
+
+// Reasons passed to failure hooks
+const (
+	ReasonMissingKey    FailureReason = "missing_key"
+	ReasonInvalidKey    FailureReason = "invalid_key"
+	ReasonInvalidScopes FailureReason = "invalid_scopes"
+	ReasonScopeDenied   FailureReason = "scope_denied"
+)
+
+// HealthServicePrefix covers the standard gRPC health service, which is served without
+// authentication so that load balancers and probes don't need an API key. Rate limits and
+// the audit log skip it too.
+const HealthServicePrefix = "/grpc.health.v1.Health/"
+
+// FailureHook is called when a request is rejected
+type FailureHook func(ctx context.Context, fullMethod string, reason FailureReason)
+
+// Option configures the auth interceptors
+type Option func(*options)
+
+type options struct {
+	onFailure []FailureHook
+	trusted   []func(ctx context.Context) bool
+	guard     *Guard
+
+	signatures        *SignatureVerifier
+	requireSignatures bool
+}
+
+// WithFailureHook calls hook for every rejected request, e.g. to count auth failures
+func WithFailureHook(hook FailureHook) Option {
+	return func(o *options) {
+		o.onFailure = append(o.onFailure, hook)
+	}
+}
+
+// WithTrustedPeer lets calls for which trusted returns true through without an API key
+// and without scope restrictions, e.g. local processes identified by their UID
+func WithTrustedPeer(trusted func(ctx context.Context) bool) Option {
+	return func(o *options) {
+		o.trusted = append(o.trusted, trusted)
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // UnaryServerInterceptor returns a gRPC unary interceptor for API key validation
-func UnaryServerInterceptor(validator Validator) grpc.UnaryServerInterceptor {
+func UnaryServerInterceptor(validator Validator, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
 	return func(
 		ctx context.Context,
 		req interface{},
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		apiKey, err := ExtractAPIKey(ctx)
+		ctx, _, err := o.authorize(ctx, validator, info.FullMethod)
 		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, "missing API key")
-		}
-
-		if !validator.Validate(apiKey) {
-			return nil, status.Error(codes.PermissionDenied, "invalid API key")
+			return nil, err
 		}
 
 		return handler(ctx, req)
@@ -30,22 +85,160 @@ func UnaryServerInterceptor(validator Validator) grpc.UnaryServerInterceptor {
 }
 
 // StreamServerInterceptor returns a gRPC stream interceptor for API key validation
-func StreamServerInterceptor(validator Validator) grpc.StreamServerInterceptor {
+func StreamServerInterceptor(validator Validator, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
 	return func(
 		srv interface{},
 		ss grpc.ServerStream,
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		apiKey, err := ExtractAPIKey(ss.Context())
+		ctx, apiKey, err := o.authorize(ss.Context(), validator, info.FullMethod)
 		if err != nil {
-			return status.Error(codes.Unauthenticated, "missing API key")
+			return err
+		}
+		if apiKey == "" {
+			return handler(srv, &scopedServerStream{ServerStream: ss, ctx: ctx})
+		}
+
+		// 스트림이 열려 있는 동안 키가 폐기되거나 만료되면 스트림을 끊음
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		go recheckKey(ctx, cancel, validator, apiKey)
+
+		err = handler(srv, &scopedServerStream{ServerStream: ss, ctx: ctx})
+		if errors.Is(context.Cause(ctx), errKeyRevoked) {
+			o.failed(ctx, info.FullMethod, ReasonInvalidKey)
+			return status.Error(codes.PermissionDenied, "API key was revoked or expired")
+		}
+		return err
+	}
+}
+
+// keyRecheckInterval is how often open streams check that their API key is still valid
+const keyRecheckInterval = 5 * time.Second
+
+var errKeyRevoked = errors.New("API key was revoked or expired")
+
+// recheckKey cancels ctx with errKeyRevoked once apiKey is no longer valid
+func recheckKey(ctx context.Context, cancel context.CancelCauseFunc, validator Validator, apiKey string) {
+	ticker := time.NewTicker(keyRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
 
 		if !validator.Validate(apiKey) {
-			return status.Error(codes.PermissionDenied, "invalid API key")
+			cancel(errKeyRevoked)
+			return
+		}
+	}
+}
+
+// authorize validates the API key in ctx and enforces its scopes for fullMethod. It
+// returns the key, or "" for calls let in without one.
+func (o *options) authorize(ctx context.Context, validator Validator, fullMethod string) (context.Context, string, error) {
+	// 거부 목록은 헬스 체크에도 적용됨
+	if o.guard != nil {
+		if err := o.guard.denied(ctx); err != nil {
+			o.failed(ctx, fullMethod, ReasonDeniedAddress)
+			return nil, "", err
 		}
+	}
+
+	if strings.HasPrefix(fullMethod, HealthServicePrefix) {
+		return ctx, "", nil
+	}
+
+	if o.guard != nil {
+		if reason, err := o.guard.check(ctx, time.Now()); err != nil {
+			o.failed(ctx, fullMethod, reason)
+			return nil, "", err
+		}
+	}
+
+	for _, trusted := range o.trusted {
+		if trusted(ctx) {
+			return ctx, "", nil
+		}
+	}
+
+	ctx, apiKey, reason, err := o.apiKey(ctx, fullMethod)
+	if err == nil {
+		ctx, reason, err = authorize(ctx, validator, apiKey, fullMethod)
+	}
+	if err != nil {
+		// 키를 추측하는 경우만 잠금 대상 (키 누락이나 scope 위반은 제외)
+		if o.guard != nil && (reason == ReasonInvalidKey || reason == ReasonInvalidSignature) {
+			o.guard.failed(ctx, time.Now())
+		}
+		o.failed(ctx, fullMethod, reason)
+		return nil, "", err
+	}
+
+	return withVerifiedKey(ctx, apiKey), apiKey, nil
+}
+
+func (o *options) failed(ctx context.Context, fullMethod string, reason FailureReason) {
+	for _, hook := range o.onFailure {
+		hook(ctx, fullMethod, reason)
+	}
+}
+
+// apiKey returns the key of the call: the key that signed it, or the one in its metadata
+func (o *options) apiKey(ctx context.Context, fullMethod string) (context.Context, string, FailureReason, error) {
+	if o.signatures != nil && isSigned(ctx) {
+		key, reason, err := o.signatures.verify(ctx, fullMethod, time.Now())
+		if err != nil {
+			return ctx, "", reason, err
+		}
+		return withVerifiedKey(ctx, key), key, "", nil
+	}
+
+	apiKey, err := ExtractAPIKey(ctx)
+	if err != nil {
+		return ctx, "", ReasonMissingKey, status.Error(codes.Unauthenticated, "missing API key")
+	}
+	if o.requireSignatures {
+		return ctx, "", ReasonSignatureRequired, status.Error(codes.Unauthenticated, "requests must be signed instead of sending the API key")
+	}
+
+	return ctx, apiKey, "", nil
+}
+
+func authorize(ctx context.Context, validator Validator, apiKey, fullMethod string) (context.Context, FailureReason, error) {
+	if !validator.Validate(apiKey) {
+		return ctx, ReasonInvalidKey, status.Error(codes.PermissionDenied, "invalid API key")
+	}
 
-		return handler(srv, ss)
+	provider, ok := validator.(ScopeProvider)
+	if !ok {
+		return ctx, "", nil
 	}
+
+	scope, err := ParseScopes(provider.Scopes(apiKey))
+	if err != nil {
+		return ctx, ReasonInvalidScopes, status.Error(codes.PermissionDenied, "API key has invalid scopes")
+	}
+
+	if !scope.AllowsMethod(fullMethod) {
+		return ctx, ReasonScopeDenied, status.Errorf(codes.PermissionDenied, "API key is not allowed to call %s", fullMethod)
+	}
+
+	return WithScope(ctx, scope), "", nil
+}
+
+// scopedServerStream overrides the stream context so handlers can read the key scope
+type scopedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedServerStream) Context() context.Context {
+	return s.ctx
 }
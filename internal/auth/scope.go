@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/binaryarc/watcher/proto"
+)
+
+const (
+	// RuntimeScopePrefix restricts a key to the named runtime (e.g. "runtime:java")
+	RuntimeScopePrefix = "runtime:"
+	// RPCScopePrefix restricts a key to the named RPC (e.g. "rpc:ObserveRuntimes")
+	RPCScopePrefix = "rpc:"
+	// PushScopePrefix lets a key push observations to a collector for the hosts matching
+	// a glob (e.g. "push:web-12" or "push:edge-*"). Keys without it can't push, and keys
+	// with it but no RPC scope can do nothing else.
+	PushScopePrefix = "push:"
+)
+
+// ScopeProvider is implemented by validators that can restrict what a key may access
+type ScopeProvider interface {
+	Scopes(key string) []string
+}
+
+// Scope describes what a validated API key is allowed to access.
+// An empty list means the key is not restricted in that dimension.
+type Scope struct {
+	Runtimes []string
+	RPCs     []string
+	// PushHosts are the hosts the key may push observations for; empty means none
+	PushHosts []string
+}
+
+type scopeContextKey struct{}
+
+// ParseScopes converts scope strings such as "runtime:java" or "rpc:ObserveRuntimes" into a Scope
+func ParseScopes(scopes []string) (Scope, error) {
+	var scope Scope
+
+	for _, raw := range scopes {
+		s := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(s, RuntimeScopePrefix):
+			name := strings.TrimPrefix(s, RuntimeScopePrefix)
+			if name == "" {
+				return Scope{}, fmt.Errorf("invalid scope %q: missing runtime name", raw)
+			}
+			scope.Runtimes = append(scope.Runtimes, name)
+		case strings.HasPrefix(s, RPCScopePrefix):
+			name := strings.TrimPrefix(s, RPCScopePrefix)
+			if name == "" {
+				return Scope{}, fmt.Errorf("invalid scope %q: missing RPC name", raw)
+			}
+			scope.RPCs = append(scope.RPCs, name)
+		case strings.HasPrefix(s, PushScopePrefix):
+			pattern := strings.TrimPrefix(s, PushScopePrefix)
+			if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+				return Scope{}, fmt.Errorf("invalid scope %q: expected a host name or pattern", raw)
+			}
+			scope.PushHosts = append(scope.PushHosts, pattern)
+		default:
+			return Scope{}, fmt.Errorf("invalid scope %q: expected %q, %q or %q prefix", raw, RuntimeScopePrefix, RPCScopePrefix, PushScopePrefix)
+		}
+	}
+
+	return scope, nil
+}
+
+// IsUnrestricted returns true if the scope does not limit access
+func (s Scope) IsUnrestricted() bool {
+	return len(s.Runtimes) == 0 && len(s.RPCs) == 0 && len(s.PushHosts) == 0
+}
+
+// AllowsMethod reports whether the scope permits calling the given gRPC method.
+// RPC scopes may name either the full method ("/watcher.WatcherService/ObserveRuntimes")
+// or just the method name ("ObserveRuntimes"). A scope with push hosts but no RPCs
+// only permits pushing observations.
+func (s Scope) AllowsMethod(fullMethod string) bool {
+	if len(s.RPCs) == 0 {
+		// push 전용 키는 에이전트 키이므로 관측 전송 외에는 허용하지 않음
+		if len(s.PushHosts) > 0 {
+			return fullMethod == proto.CollectorService_PushObservations_FullMethodName
+		}
+		return true
+	}
+
+	shortName := fullMethod
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		shortName = fullMethod[idx+1:]
+	}
+
+	for _, rpc := range s.RPCs {
+		if rpc == fullMethod || rpc == shortName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsPush reports whether the key may push observations for host
+func (s Scope) AllowsPush(host string) bool {
+	for _, pattern := range s.PushHosts {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NarrowRuntimes restricts a requested runtime filter to the runtimes allowed by the scope.
+// An empty requested filter means "all runtimes". The returned bool is false when the
+// scope leaves nothing to observe.
+func (s Scope) NarrowRuntimes(requested []string) ([]string, bool) {
+	if len(s.Runtimes) == 0 {
+		return requested, true
+	}
+
+	if len(requested) == 0 {
+		return s.Runtimes, true
+	}
+
+	allowed := make(map[string]bool)
+	for _, name := range s.Runtimes {
+		allowed[name] = true
+	}
+
+	var narrowed []string
+	for _, name := range requested {
+		if allowed[name] {
+			narrowed = append(narrowed, name)
+		}
+	}
+
+	return narrowed, len(narrowed) > 0
+}
+
+// WithScope attaches a key scope to the context
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+// ScopeFromContext returns the key scope attached by the auth interceptors
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(scopeContextKey{}).(Scope)
+	return scope, ok
+}
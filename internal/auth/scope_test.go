@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// scopedKeys validates the keys it holds and reports their scopes
+type scopedKeys map[string][]string
+
+func (k scopedKeys) Validate(key string) bool {
+	_, ok := k[key]
+	return ok
+}
+
+func (k scopedKeys) Scopes(key string) []string {
+	return k[key]
+}
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		scopes  []string
+		want    Scope
+		wantErr bool
+	}{
+		{scopes: nil, want: Scope{}},
+		{scopes: []string{"runtime:java", " runtime:python "}, want: Scope{Runtimes: []string{"java", "python"}}},
+		{scopes: []string{"rpc:ObserveRuntimes", "rpc:/watcher.WatcherService/WatchRuntimes"}, want: Scope{RPCs: []string{"ObserveRuntimes", "/watcher.WatcherService/WatchRuntimes"}}},
+		{scopes: []string{"push:edge-*", "runtime:go"}, want: Scope{Runtimes: []string{"go"}, PushHosts: []string{"edge-*"}}},
+		{scopes: []string{"runtime:"}, wantErr: true},
+		{scopes: []string{"rpc:"}, wantErr: true},
+		{scopes: []string{"push:"}, wantErr: true},
+		{scopes: []string{"push:web-["}, wantErr: true},
+		{scopes: []string{"java"}, wantErr: true},
+		{scopes: []string{"admin:*"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.scopes)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseScopes(%q) error = %v, want error %v", tt.scopes, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseScopes(%q) = %+v, want %+v", tt.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestScopeAllowsMethod(t *testing.T) {
+	const (
+		observe = "/watcher.WatcherService/ObserveRuntimes"
+		watch   = "/watcher.WatcherService/WatchRuntimes"
+		push    = "/watcher.CollectorService/PushObservations"
+		query   = "/watcher.CollectorService/QueryFleet"
+	)
+
+	tests := []struct {
+		name   string
+		scope  Scope
+		method string
+		want   bool
+	}{
+		{name: "unrestricted", scope: Scope{}, method: watch, want: true},
+		{name: "runtime scope allows every method", scope: Scope{Runtimes: []string{"java"}}, method: watch, want: true},
+		{name: "short name", scope: Scope{RPCs: []string{"ObserveRuntimes"}}, method: observe, want: true},
+		{name: "full name", scope: Scope{RPCs: []string{observe}}, method: observe, want: true},
+		{name: "unlisted method", scope: Scope{RPCs: []string{"ObserveRuntimes"}}, method: watch, want: false},
+		{name: "same name in another service", scope: Scope{RPCs: []string{"/other.Service/ObserveRuntimes"}}, method: observe, want: false},
+		{name: "push-only key pushes", scope: Scope{PushHosts: []string{"edge-*"}}, method: push, want: true},
+		{name: "push-only key can't observe", scope: Scope{PushHosts: []string{"edge-*"}}, method: observe, want: false},
+		{name: "push-only key can't query the fleet", scope: Scope{PushHosts: []string{"edge-*"}}, method: query, want: false},
+		{name: "push and runtime scopes", scope: Scope{Runtimes: []string{"go"}, PushHosts: []string{"edge-*"}}, method: observe, want: false},
+		{name: "push with listed RPCs", scope: Scope{RPCs: []string{"QueryFleet"}, PushHosts: []string{"edge-*"}}, method: query, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.AllowsMethod(tt.method); got != tt.want {
+				t.Errorf("AllowsMethod(%q) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeNarrowRuntimes(t *testing.T) {
+	tests := []struct {
+		name        string
+		scope       Scope
+		requested   []string
+		want        []string
+		wantAllowed bool
+	}{
+		{name: "unrestricted keeps the request", scope: Scope{}, requested: []string{"go"}, want: []string{"go"}, wantAllowed: true},
+		{name: "unrestricted and no filter", scope: Scope{}, wantAllowed: true},
+		{name: "no filter means every allowed runtime", scope: Scope{Runtimes: []string{"java", "python"}}, want: []string{"java", "python"}, wantAllowed: true},
+		{name: "filter is narrowed", scope: Scope{Runtimes: []string{"java"}}, requested: []string{"java", "go"}, want: []string{"java"}, wantAllowed: true},
+		{name: "nothing allowed", scope: Scope{Runtimes: []string{"java"}}, requested: []string{"go"}, wantAllowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allowed := tt.scope.NarrowRuntimes(tt.requested)
+			if !reflect.DeepEqual(got, tt.want) || allowed != tt.wantAllowed {
+				t.Errorf("NarrowRuntimes(%q) = %q, %v, want %q, %v", tt.requested, got, allowed, tt.want, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestInterceptorEnforcesScopes(t *testing.T) {
+	keys := scopedKeys{
+		"full":    nil,
+		"observe": {"rpc:ObserveRuntimes", "runtime:java"},
+		"broken":  {"everything"},
+		"agent":   {"push:edge-*"},
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		method     string
+		wantCode   codes.Code
+		wantReason FailureReason
+		wantScope  Scope
+	}{
+		{name: "unrestricted key", key: "full", method: "/watcher.WatcherService/WatchRuntimes", wantCode: codes.OK},
+		{name: "listed method", key: "observe", method: "/watcher.WatcherService/ObserveRuntimes", wantCode: codes.OK,
+			wantScope: Scope{Runtimes: []string{"java"}, RPCs: []string{"ObserveRuntimes"}}},
+		{name: "unlisted method", key: "observe", method: "/watcher.WatcherService/RotateKey", wantCode: codes.PermissionDenied, wantReason: ReasonScopeDenied},
+		{name: "push-only key pushes", key: "agent", method: "/watcher.CollectorService/PushObservations", wantCode: codes.OK,
+			wantScope: Scope{PushHosts: []string{"edge-*"}}},
+		{name: "push-only key observes", key: "agent", method: "/watcher.WatcherService/ObserveRuntimes", wantCode: codes.PermissionDenied, wantReason: ReasonScopeDenied},
+		{name: "push-only key queries the fleet", key: "agent", method: "/watcher.CollectorService/QueryFleet", wantCode: codes.PermissionDenied, wantReason: ReasonScopeDenied},
+		{name: "invalid stored scopes", key: "broken", method: "/watcher.WatcherService/ObserveRuntimes", wantCode: codes.PermissionDenied, wantReason: ReasonInvalidScopes},
+		{name: "unknown key", key: "guess", method: "/watcher.WatcherService/ObserveRuntimes", wantCode: codes.PermissionDenied, wantReason: ReasonInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason FailureReason
+			interceptor := UnaryServerInterceptor(keys, WithFailureHook(func(ctx context.Context, fullMethod string, r FailureReason) {
+				reason = r
+			}))
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(APIKeyHeader, tt.key))
+			var scope Scope
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				scope, _ = ScopeFromContext(ctx)
+				return nil, nil
+			})
+
+			if status.Code(err) != tt.wantCode || reason != tt.wantReason {
+				t.Fatalf("interceptor = %v (reason %q), want %v (reason %q)", err, reason, tt.wantCode, tt.wantReason)
+			}
+			if err == nil && !reflect.DeepEqual(scope, tt.wantScope) {
+				t.Errorf("handler scope = %+v, want %+v", scope, tt.wantScope)
+			}
+		})
+	}
+}
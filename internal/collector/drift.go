@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/comparison"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/binaryarc/watcher/internal/notify"
+)
+
+// DriftMonitor periodically compares every runtime across agents and reports when
+// a comparison status flips, e.g. from SAME to DIFF
+type DriftMonitor struct {
+	Store *Store
+	// Selectors choose the agents compared together; "" compares all agents
+	Selectors []string
+	Interval  time.Duration
+	OnChange  func(events []notify.Event)
+
+	// selector → runtime → status of the previous check
+	previous map[string]map[string]string
+}
+
+// Run checks statuses every Interval until ctx is cancelled.
+// The first check only records the current statuses.
+func (m *DriftMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if events := m.check(); len(events) > 0 && m.OnChange != nil {
+				m.OnChange(events)
+			}
+		}
+	}
+}
+
+func (m *DriftMonitor) check() []notify.Event {
+	first := m.previous == nil
+	if first {
+		m.previous = make(map[string]map[string]string)
+	}
+
+	reports := m.Store.List()
+	now := time.Now()
+
+	var events []notify.Event
+	for _, raw := range m.Selectors {
+		selector, err := labels.ParseSelector(raw)
+		if err != nil {
+			continue
+		}
+
+		// 관측된 적 없는 에이전트는 비교에서 제외
+		var hosts []string
+		var servers []map[string]string
+		for _, report := range reports {
+			if report.Observation == nil || !selector.Matches(report.Labels()) {
+				continue
+			}
+
+			versions := make(map[string]string)
+			for _, rt := range report.Observation.Runtimes {
+				if rt.Found {
+					versions[rt.Name] = rt.Version
+				}
+			}
+			hosts = append(hosts, report.Host)
+			servers = append(servers, versions)
+		}
+
+		statuses := comparison.Statuses(servers)
+		previous := m.previous[raw]
+		m.previous[raw] = statuses
+
+		if first {
+			continue
+		}
+
+		for name := range union(previous, statuses) {
+			before, after := statusOrMissing(previous, name), statusOrMissing(statuses, name)
+			if before == after {
+				continue
+			}
+
+			versions := make(map[string]string, len(hosts))
+			for i, host := range hosts {
+				if version, found := servers[i][name]; found {
+					versions[host] = version
+				} else {
+					versions[host] = comparison.VersionMissing
+				}
+			}
+
+			events = append(events, notify.Event{
+				Type:           notify.StatusChanged,
+				Timestamp:      now,
+				Runtime:        name,
+				Selector:       raw,
+				PreviousStatus: before,
+				Status:         after,
+				Versions:       versions,
+			})
+		}
+	}
+
+	return events
+}
+
+func statusOrMissing(statuses map[string]string, name string) string {
+	if status, found := statuses[name]; found {
+		return status
+	}
+	return comparison.StatusMissing
+}
+
+func union(a, b map[string]string) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/grpcclient"
+)
+
+// DefaultConcurrency is how many agents a Poller observes at the same time
+const DefaultConcurrency = 32
+
+// Agent is a watcher server polled by the collector
+type Agent struct {
+	Name    string
+	Address string
+}
+
+// Poller periodically observes agents and records the results in a Store
+type Poller struct {
+	Store  *Store
+	Agents []Agent
+	APIKey string
+	// TLS is used to connect to agents; nil means plaintext
+	TLS *tls.Config
+	// SignRequests sends an HMAC signature of each call instead of the API key
+	SignRequests bool
+	Interval     time.Duration
+	Concurrency  int
+
+	// OnPoll, if non-nil, is called after every round with the number of failed agents
+	OnPoll func(total, failed int)
+}
+
+// Run polls all agents immediately and then every Interval until ctx is cancelled
+func (p *Poller) Run(ctx context.Context) {
+	for _, agent := range p.Agents {
+		p.Store.Register(agent.Name, agent.Address)
+	}
+
+	p.pollAll(ctx)
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.pollAll(ctx)
+		}
+	}
+}
+
+func (p *Poller) pollAll(ctx context.Context) {
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for _, agent := range p.Agents {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(agent Agent) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := p.poll(ctx, agent); err != nil {
+				p.Store.RecordError(agent.Name, agent.Address, err)
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(agent)
+	}
+
+	wg.Wait()
+
+	if p.OnPoll != nil {
+		p.OnPoll(len(p.Agents), failed)
+	}
+}
+
+func (p *Poller) poll(ctx context.Context, agent Agent) error {
+	client, err := grpcclient.NewClientWithOptions(agent.Address, grpcclient.Options{APIKey: p.APIKey, TLS: p.TLS, SignRequests: p.SignRequests})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	resp, err := client.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.Store.Record(agent.Name, agent.Address, resp)
+	return nil
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/grpcclient"
+	"github.com/binaryarc/watcher/proto"
+)
+
+const (
+	// DefaultMinBackoff is the first delay before reconnecting to a collector
+	DefaultMinBackoff = time.Second
+	// DefaultMaxBackoff caps the delay between reconnection attempts
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Pusher streams local observations to a collector, reconnecting with
+// exponential backoff when the collector is unreachable
+type Pusher struct {
+	Collector string
+	APIKey    string
+	// TLS is used to connect to the collector; nil means plaintext
+	TLS *tls.Config
+	// SignRequests sends an HMAC signature of each call instead of the API key
+	SignRequests bool
+	Host         string // agent name; the observed hostname if empty
+	Interval     time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+
+	// Observe produces the observation to push
+	Observe func(ctx context.Context) (*proto.ObserveResponse, error)
+
+	// OnPush and OnError, if non-nil, report progress
+	OnPush  func()
+	OnError func(err error, retryIn time.Duration)
+}
+
+// Run pushes an observation immediately and then every Interval until ctx is cancelled
+func (p *Pusher) Run(ctx context.Context) {
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff := minBackoff
+	for {
+		pushed, err := p.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// 두 번째 전송까지 했다면 첫 관측이 수락된 것이므로 backoff 초기화
+		// (거부된 관측도 Send는 성공하므로 한 번으로는 알 수 없음)
+		if pushed > 1 {
+			backoff = minBackoff
+		}
+
+		// 동시에 재접속하지 않도록 지터 추가 (backoff의 50~100%)
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if p.OnError != nil {
+			p.OnError(err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// session connects to the collector and pushes until an error occurs.
+// It returns how many observations were sent.
+func (p *Pusher) session(ctx context.Context) (int, error) {
+	client, err := grpcclient.NewClientWithOptions(p.Collector, grpcclient.Options{APIKey: p.APIKey, TLS: p.TLS, SignRequests: p.SignRequests})
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.PushObservations(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	pushed := 0
+	for {
+		observation, err := p.Observe(ctx)
+		if err != nil {
+			return pushed, fmt.Errorf("failed to observe runtimes: %w", err)
+		}
+
+		if err := stream.Send(p.Host, observation); err != nil {
+			return pushed, err
+		}
+		pushed++
+
+		if p.OnPush != nil {
+			p.OnPush()
+		}
+
+		select {
+		case <-ctx.Done():
+			stream.Close()
+			return pushed, ctx.Err()
+		case <-stream.Done():
+			_, err := stream.Close()
+			return pushed, err
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"context"
+	"io"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// errNoObservation is reported for agents that have not been observed yet
+const errNoObservation = "no observation"
+
+// Server answers fleet-wide queries from the reports in a Store
+type Server struct {
+	proto.UnimplementedCollectorServiceServer
+
+	store *Store
+}
+
+// NewServer creates a collector service backed by store
+func NewServer(store *Store) *Server {
+	return &Server{store: store}
+}
+
+// QueryFleet returns the latest reports of the requested agents, or of every agent.
+// Requested agents are returned in request order, including unknown and never
+// observed ones with an error.
+func (s *Server) QueryFleet(ctx context.Context, req *proto.QueryFleetRequest) (*proto.QueryFleetResponse, error) {
+	selector, err := labels.ParseSelector(req.Selector)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid selector: %v", err)
+	}
+
+	scope, _ := auth.ScopeFromContext(ctx)
+
+	var hosts []*proto.HostReport
+	if len(req.Hosts) == 0 {
+		for _, report := range s.store.List() {
+			// 관측된 적 없는 에이전트는 라벨을 알 수 없으므로 selector가 있으면 제외
+			if !selector.Empty() && (report.Observation == nil || !selector.Matches(report.Labels())) {
+				continue
+			}
+			hosts = append(hosts, toProto(report, scope))
+		}
+	} else {
+		for _, id := range req.Hosts {
+			report, found := s.store.Get(id)
+			if !found {
+				hosts = append(hosts, &proto.HostReport{Host: id, Error: "unknown agent"})
+				continue
+			}
+			// 관측된 적 없는 에이전트는 selector와 무관하게 오류로 보고
+			if report.Observation != nil && !selector.Matches(report.Labels()) {
+				continue
+			}
+			hosts = append(hosts, toProto(report, scope))
+		}
+	}
+
+	return &proto.QueryFleetResponse{Hosts: hosts}, nil
+}
+
+// toProto converts a report, hiding runtimes the caller's key is not scoped for.
+// Agents that were never observed are reported with an error.
+func toProto(report Report, scope auth.Scope) *proto.HostReport {
+	hostReport := &proto.HostReport{
+		Host:    report.Host,
+		Address: report.Address,
+		Error:   report.Error,
+	}
+	if !report.LastSeen.IsZero() {
+		hostReport.LastSeen = report.LastSeen.Unix()
+	}
+
+	if report.Observation == nil {
+		hostReport.Error = errNoObservation
+		if report.Error != "" {
+			hostReport.Error += ": " + report.Error
+		}
+		return hostReport
+	}
+
+	observation := report.Observation
+	if observation != nil && len(scope.Runtimes) > 0 {
+		allowed := make(map[string]bool)
+		for _, name := range scope.Runtimes {
+			allowed[name] = true
+		}
+
+		var runtimes []*proto.Runtime
+		for _, rt := range observation.Runtimes {
+			if allowed[rt.Name] {
+				runtimes = append(runtimes, rt)
+			}
+		}
+
+		observation = &proto.ObserveResponse{
+			Runtimes:        runtimes,
+			SystemInfo:      observation.SystemInfo,
+			Timestamp:       observation.Timestamp,
+			CacheAgeSeconds: observation.CacheAgeSeconds,
+		}
+	}
+	hostReport.Observation = observation
+
+	return hostReport
+}
+
+// PushObservations records observations streamed by agents that can't be polled,
+// e.g. hosts behind NAT. The agent's address is taken from the connection. Each key may
+// only push for the hosts named by its push: scopes, so one agent (or a read-only key)
+// can't overwrite the reports of others.
+func (s *Server) PushObservations(stream proto.CollectorService_PushObservationsServer) error {
+	// scope가 없으면 인증이 꺼져 있거나 신뢰된 로컬 사용자
+	scope, scoped := auth.ScopeFromContext(stream.Context())
+
+	address := ""
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		address = p.Addr.String()
+	}
+
+	var accepted int32
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&proto.PushResponse{Accepted: accepted})
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.Observation == nil {
+			return status.Error(codes.InvalidArgument, "missing observation")
+		}
+
+		host := req.Host
+		if host == "" && req.Observation.SystemInfo != nil {
+			host = req.Observation.SystemInfo.Hostname
+		}
+		if host == "" {
+			return status.Error(codes.InvalidArgument, "missing host name")
+		}
+		if scoped && !scope.AllowsPush(host) {
+			return status.Errorf(codes.PermissionDenied, "API key is not allowed to push observations for %s (needs scope %s%s)", host, auth.PushScopePrefix, host)
+		}
+
+		s.store.Record(host, address, req.Observation)
+		accepted++
+	}
+}
+
+// GetHistory returns the timeline of runtime changes of one agent
+func (s *Server) GetHistory(ctx context.Context, req *proto.HistoryRequest) (*proto.HistoryResponse, error) {
+	h := s.store.History()
+	if h == nil {
+		return nil, status.Error(codes.Unimplemented, "history is not enabled on this collector")
+	}
+
+	if req.Host == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing host")
+	}
+
+	host := req.Host
+	if report, found := s.store.Get(req.Host); found {
+		host = report.Host
+	}
+
+	// 키 scope에 없는 런타임의 이력은 보여주지 않음
+	scope, _ := auth.ScopeFromContext(ctx)
+	var runtimeFilter []string
+	if req.Runtime != "" {
+		runtimeFilter = []string{req.Runtime}
+	}
+	allowed, ok := scope.NarrowRuntimes(runtimeFilter)
+	if !ok {
+		return &proto.HistoryResponse{Host: host}, nil
+	}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		allowedSet[name] = true
+	}
+
+	changes, err := h.Changes(host, req.Runtime)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read history: %v", err)
+	}
+
+	resp := &proto.HistoryResponse{Host: host}
+	for _, change := range changes {
+		if len(allowedSet) > 0 && !allowedSet[change.Runtime] {
+			continue
+		}
+		resp.Changes = append(resp.Changes, &proto.RuntimeChange{
+			Timestamp:       change.Timestamp,
+			Runtime:         change.Runtime,
+			PreviousVersion: change.PreviousVersion,
+			Version:         change.Version,
+			Path:            change.Path,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/binaryarc/watcher/proto"
+)
+
+func TestQueryFleetUnobservedHosts(t *testing.T) {
+	store := NewStore()
+	store.Register("new", "10.0.0.1:9090")
+	store.RecordError("down", "10.0.0.2:9090", errors.New("connection refused"))
+	store.Record("web", "10.0.0.3:9090", &proto.ObserveResponse{
+		SystemInfo: &proto.SystemInfo{Labels: map[string]string{"env": "prod"}},
+	})
+	s := NewServer(store)
+
+	tests := []struct {
+		name     string
+		hosts    []string
+		selector string
+		want     map[string]string // host -> error
+	}{
+		{name: "every agent", want: map[string]string{"down": "no observation: connection refused", "new": "no observation", "web": ""}},
+		{name: "every agent matching a selector", selector: "env=prod", want: map[string]string{"web": ""}},
+		{name: "requested agents", hosts: []string{"new", "10.0.0.2:9090", "web", "gone"},
+			want: map[string]string{"new": "no observation", "gone": "unknown agent", "down": "no observation: connection refused", "web": ""}},
+		{name: "requested agents with a selector", hosts: []string{"new", "down", "web"}, selector: "env=dev",
+			want: map[string]string{"new": "no observation", "down": "no observation: connection refused"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.QueryFleet(context.Background(), &proto.QueryFleetRequest{Hosts: tt.hosts, Selector: tt.selector})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string)
+			for _, host := range resp.Hosts {
+				got[host.Host] = host.Error
+				if (host.Observation == nil) != (host.Error != "") {
+					t.Errorf("%s: observation %v with error %q", host.Host, host.Observation, host.Error)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryFleet() errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/proto"
+)
+
+// Report is the latest state a collector holds for one agent
+type Report struct {
+	Host        string
+	Address     string
+	Observation *proto.ObserveResponse // nil until the agent was observed once
+	LastSeen    time.Time
+	Error       string // error of the last poll, cleared by the next success
+}
+
+// Labels returns the labels the agent advertised in its last observation
+func (r *Report) Labels() map[string]string {
+	return observationLabels(r.Observation)
+}
+
+func observationLabels(observation *proto.ObserveResponse) map[string]string {
+	if observation == nil || observation.SystemInfo == nil {
+		return nil
+	}
+	return observation.SystemInfo.Labels
+}
+
+// Store keeps the latest report of every agent
+type Store struct {
+	mu      sync.RWMutex
+	reports map[string]*Report
+
+	history        *history.Store
+	onHistoryError func(error)
+	onChange       ChangeHandler
+}
+
+// ChangeHandler is called with the runtime changes between two observations of an agent
+type ChangeHandler func(host string, labels map[string]string, changes []history.Change)
+
+// StoreOption configures a Store
+type StoreOption func(*Store)
+
+// WithHistory records every distinct observation in a history log.
+// onError, if non-nil, is called when an observation can't be written.
+func WithHistory(h *history.Store, onError func(error)) StoreOption {
+	return func(s *Store) {
+		s.history = h
+		s.onHistoryError = onError
+	}
+}
+
+// WithChangeHandler calls fn whenever an agent's runtimes differ from its previous observation
+func WithChangeHandler(fn ChangeHandler) StoreOption {
+	return func(s *Store) {
+		s.onChange = fn
+	}
+}
+
+// NewStore creates an empty report store
+func NewStore(opts ...StoreOption) *Store {
+	s := &Store{
+		reports: make(map[string]*Report),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// History returns the history log, or nil if history is not recorded
+func (s *Store) History() *history.Store {
+	return s.history
+}
+
+// Register adds an agent that has not reported yet
+func (s *Store) Register(host, address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.reports[host]; !exists {
+		s.reports[host] = &Report{Host: host, Address: address}
+	}
+}
+
+// Record stores a successful observation of an agent
+func (s *Store) Record(host, address string, observation *proto.ObserveResponse) {
+	s.mu.Lock()
+	var previous *proto.ObserveResponse
+	if report, exists := s.reports[host]; exists {
+		previous = report.Observation
+	}
+	s.reports[host] = &Report{
+		Host:        host,
+		Address:     address,
+		Observation: observation,
+		LastSeen:    time.Now(),
+	}
+	s.mu.Unlock()
+
+	// 첫 관측은 기준점이므로 변경으로 보지 않음
+	if s.onChange != nil && previous != nil {
+		changes := history.Diff(history.FromProto(previous.Runtimes), history.FromProto(observation.Runtimes))
+		for i := range changes {
+			changes[i].Timestamp = observation.Timestamp
+		}
+		if len(changes) > 0 {
+			s.onChange(host, observationLabels(observation), changes)
+		}
+	}
+
+	if s.history == nil {
+		return
+	}
+
+	if _, err := s.history.Record(host, observation); err != nil && s.onHistoryError != nil {
+		s.onHistoryError(err)
+	}
+}
+
+// RecordError stores a failed observation. The previous observation is kept.
+func (s *Store) RecordError(host, address string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &Report{Host: host, Address: address, Error: err.Error()}
+	if previous, exists := s.reports[host]; exists {
+		report.Observation = previous.Observation
+		report.LastSeen = previous.LastSeen
+	}
+	s.reports[host] = report
+}
+
+// Get finds the report of an agent by name or address
+func (s *Store) Get(id string) (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if report, exists := s.reports[id]; exists {
+		return *report, true
+	}
+
+	for _, report := range s.reports {
+		if report.Address == id {
+			return *report, true
+		}
+	}
+
+	return Report{}, false
+}
+
+// List returns all reports sorted by host
+func (s *Store) List() []Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make([]Report, 0, len(s.reports))
+	for _, report := range s.reports {
+		reports = append(reports, *report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Host < reports[j].Host
+	})
+
+	return reports
+}
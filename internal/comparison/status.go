@@ -0,0 +1,83 @@
+package comparison
+
+// Comparison statuses of a runtime across servers
+const (
+	StatusSame    = "SAME"
+	StatusDiff    = "DIFF"
+	StatusPartial = "PARTIAL"
+	StatusMissing = "MISSING"
+	StatusError   = "ERROR"
+)
+
+// Version placeholders used in comparisons
+const (
+	VersionError   = "ERROR" // the server could not be queried
+	VersionMissing = "x"     // the runtime is not installed on the server
+)
+
+// DetermineStatus returns the status of a runtime given its version on every server
+func DetermineStatus(versions []string) string {
+	if len(versions) == 0 {
+		return StatusMissing
+	}
+
+	uniqueVersions := make(map[string]struct{})
+	hasVersion := false
+	hasMissing := false
+
+	for _, version := range versions {
+		switch version {
+		case VersionError:
+			return StatusError
+		case VersionMissing:
+			hasMissing = true
+		default:
+			hasVersion = true
+			uniqueVersions[version] = struct{}{}
+		}
+	}
+
+	if !hasVersion && hasMissing {
+		return StatusMissing
+	}
+
+	if hasMissing {
+		return StatusPartial
+	}
+
+	if len(uniqueVersions) > 1 {
+		return StatusDiff
+	}
+
+	if len(uniqueVersions) == 1 {
+		return StatusSame
+	}
+
+	return StatusMissing
+}
+
+// Statuses returns the status of every runtime found on any server.
+// Each element of servers maps runtime names to versions.
+func Statuses(servers []map[string]string) map[string]string {
+	names := make(map[string]bool)
+	for _, runtimes := range servers {
+		for name := range runtimes {
+			names[name] = true
+		}
+	}
+
+	statuses := make(map[string]string, len(names))
+	for name := range names {
+		versions := make([]string, len(servers))
+		for i, runtimes := range servers {
+			if version, found := runtimes[name]; found {
+				versions[i] = version
+			} else {
+				versions[i] = VersionMissing
+			}
+		}
+		statuses[name] = DetermineStatus(versions)
+	}
+
+	return statuses
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClientConfig is the wctl configuration file (~/.watcher/config).
+// Like a kubeconfig it names servers, groups them, and selects defaults through contexts.
+type ClientConfig struct {
+	CurrentContext string              `yaml:"current-context,omitempty"`
+	Servers        map[string]*Server  `yaml:"servers,omitempty"`
+	Groups         map[string][]string `yaml:"groups,omitempty"`
+	Contexts       map[string]*Context `yaml:"contexts,omitempty"`
+
+	path string
+}
+
+// Server is a named watcher server
+type Server struct {
+	Address string            `yaml:"address"`
+	Key     string            `yaml:"key,omitempty"` // key name saved with wctl key
+	TLS     *TLSConfig        `yaml:"tls,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+	// SignRequests sends an HMAC signature instead of the key, for servers without TLS
+	SignRequests bool `yaml:"sign-requests,omitempty"`
+}
+
+// TLSConfig holds client TLS settings for a server
+type TLSConfig struct {
+	CAFile             string `yaml:"ca-file,omitempty"`
+	CertFile           string `yaml:"cert-file,omitempty"`
+	KeyFile            string `yaml:"key-file,omitempty"`
+	ServerName         string `yaml:"server-name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+// Context selects the default targets and key for wctl commands
+type Context struct {
+	Group     string   `yaml:"group,omitempty"`
+	Servers   []string `yaml:"servers,omitempty"`
+	Key       string   `yaml:"key,omitempty"`
+	Collector string   `yaml:"collector,omitempty"` // fleet collector queried instead of each server
+}
+
+// DefaultClientConfigPath returns $WATCHER_CONFIG or ~/.watcher/config
+func DefaultClientConfigPath() (string, error) {
+	if envPath := os.Getenv("WATCHER_CONFIG"); envPath != "" {
+		return envPath, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".watcher", "config"), nil
+}
+
+// LoadClient reads a wctl configuration file. A missing file yields an empty configuration.
+func LoadClient(path string) (*ClientConfig, error) {
+	cfg := &ClientConfig{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	// "name:"처럼 값이 없는 context는 빈 context로 취급
+	for name, ctx := range cfg.Contexts {
+		if ctx == nil {
+			cfg.Contexts[name] = &Context{}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Save writes the configuration back to the file it was loaded from
+func (c *ClientConfig) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := c.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// Marshal encodes the configuration as YAML
+func (c *ClientConfig) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Path returns the file the configuration was loaded from
+func (c *ClientConfig) Path() string {
+	return c.path
+}
+
+// Context returns the named context, or the current context when name is empty
+func (c *ClientConfig) Context(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	ctx, ok := c.Contexts[name]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found", name)
+	}
+
+	return ctx, nil
+}
+
+// ContextNames returns all context names in sorted order
+func (c *ClientConfig) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for name := range c.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Group returns the server names of a group
+func (c *ClientConfig) Group(name string) ([]string, error) {
+	members, ok := c.Groups[name]
+	if !ok {
+		return nil, fmt.Errorf("group %q not found", name)
+	}
+	return members, nil
+}
+
+// LookupServer finds a server by name or by address. If several servers share the
+// address, the first name in sorted order wins.
+func (c *ClientConfig) LookupServer(host string) (string, *Server) {
+	if server, ok := c.Servers[host]; ok {
+		return host, server
+	}
+
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if server := c.Servers[name]; server != nil && server.Address == host {
+			return name, server
+		}
+	}
+
+	return "", nil
+}
+
+// Validate checks that groups and contexts only refer to defined servers and groups
+func (c *ClientConfig) Validate() error {
+	for name, server := range c.Servers {
+		if server == nil || server.Address == "" {
+			return fmt.Errorf("server %q: address is required", name)
+		}
+	}
+
+	for name, members := range c.Groups {
+		for _, member := range members {
+			if _, ok := c.Servers[member]; !ok {
+				return fmt.Errorf("group %q: unknown server %q", name, member)
+			}
+		}
+	}
+
+	for name, ctx := range c.Contexts {
+		if ctx == nil {
+			return fmt.Errorf("context %q is empty", name)
+		}
+		if ctx.Group != "" {
+			if _, ok := c.Groups[ctx.Group]; !ok {
+				return fmt.Errorf("context %q: unknown group %q", name, ctx.Group)
+			}
+		}
+	}
+
+	if c.CurrentContext != "" {
+		if _, ok := c.Contexts[c.CurrentContext]; !ok {
+			return fmt.Errorf("current context %q not found", c.CurrentContext)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadClientEmptyContext(t *testing.T) {
+	path := writeConfig(t, `
+current-context: dev
+contexts:
+  dev:
+  prod:
+    servers: [web-1]
+servers:
+  web-1:
+    address: 10.0.0.1:9090
+`)
+
+	cfg, err := LoadClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// 값이 없는 context도 다른 context처럼 읽을 수 있음
+	for _, name := range cfg.ContextNames() {
+		ctx, err := cfg.Context(name)
+		if err != nil || ctx == nil {
+			t.Fatalf("Context(%q) = %v, %v", name, ctx, err)
+		}
+	}
+	if ctx, _ := cfg.Context(""); ctx.Group != "" || len(ctx.Servers) != 0 {
+		t.Errorf("current context = %+v, want an empty context", ctx)
+	}
+
+	cfg.Contexts["broken"] = nil
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a nil context")
+	}
+}
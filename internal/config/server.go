@@ -0,0 +1,469 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/labels"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultServerConfigPath is where wsctl looks for its configuration file
+	DefaultServerConfigPath = "/etc/watcher/wsctl.yaml"
+
+	// EnvPrefix starts the environment variables overriding the configuration file.
+	// A setting's variable is its path in the file in upper case, e.g. WATCHER_HTTP_LISTEN
+	// for http.listen or WATCHER_DETECTORS_TIMEOUT for detectors.timeout.
+	EnvPrefix = "WATCHER_"
+)
+
+// ServerConfig is the wsctl configuration file
+type ServerConfig struct {
+	// Listen is host:port or unix:///path/to/socket
+	Listen string       `yaml:"listen,omitempty"`
+	Socket SocketConfig `yaml:"socket,omitempty"`
+	HTTP   HTTPConfig   `yaml:"http,omitempty"`
+	TLS    ServerTLS    `yaml:"tls,omitempty"`
+
+	Keystore string      `yaml:"keystore,omitempty"`
+	Auth     AuthConfig  `yaml:"auth,omitempty"`
+	Limits   LimitConfig `yaml:"limits,omitempty"`
+
+	Detectors     DetectorConfig `yaml:"detectors,omitempty"`
+	Cache         CacheConfig    `yaml:"cache,omitempty"`
+	WatchInterval time.Duration  `yaml:"watch-interval,omitempty"`
+	// MinWatchInterval is the shortest interval watch clients may ask for
+	MinWatchInterval time.Duration `yaml:"min-watch-interval,omitempty"`
+
+	// Labels are advertised to clients for selector-based targeting
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	Metrics MetricsConfig `yaml:"metrics,omitempty"`
+	Logging LoggingConfig `yaml:"logging,omitempty"`
+	Audit   AuditConfig   `yaml:"audit,omitempty"`
+
+	ShutdownTimeout time.Duration `yaml:"shutdown-timeout,omitempty"`
+	PIDFile         string        `yaml:"pidfile,omitempty"`
+
+	// Notifications are sent when runtimes change or fleet comparisons flip
+	Notifications []Notification `yaml:"notifications,omitempty"`
+
+	// set holds the keys given in the file or the environment, e.g. "auth.reload-interval"
+	set map[string]bool
+}
+
+// IsSet reports whether a setting, named by its dotted yaml key such as
+// "auth.reload-interval", was given in the config file or the environment. It tells
+// an explicit zero apart from a missing setting.
+func (c *ServerConfig) IsSet(key string) bool {
+	return c.set[key]
+}
+
+// SocketConfig applies when listening on a Unix socket
+type SocketConfig struct {
+	Mode  string `yaml:"mode,omitempty"`  // octal, e.g. "0660"
+	Group string `yaml:"group,omitempty"` // name or GID
+	// TrustUIDs are users let in without an API key (SO_PEERCRED)
+	TrustUIDs []string `yaml:"trust-uids,omitempty"`
+}
+
+// HTTPConfig enables the HTTP/JSON API
+type HTTPConfig struct {
+	// Listen is host:port; the same address as the gRPC listener shares its port
+	Listen string `yaml:"listen,omitempty"`
+}
+
+// ServerTLS enables TLS on the gRPC and HTTP listeners
+type ServerTLS struct {
+	CertFile string `yaml:"cert-file,omitempty"`
+	KeyFile  string `yaml:"key-file,omitempty"`
+	// ClientCAFile requires clients to present a certificate signed by this CA
+	ClientCAFile string `yaml:"client-ca-file,omitempty"`
+}
+
+// Enabled reports whether TLS is configured
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Authentication modes: which credentials the server accepts
+const (
+	AuthModeAPIKey = "api-key" // the key itself in x-api-key
+	AuthModeHMAC   = "hmac"    // requests signed with the key
+	AuthModeAny    = "any"
+)
+
+// AuthConfig configures API key authentication
+type AuthConfig struct {
+	Disabled         bool          `yaml:"disabled,omitempty"`
+	Mode             string        `yaml:"mode,omitempty"`
+	SignatureSkew    time.Duration `yaml:"signature-skew,omitempty"`
+	ReloadInterval   time.Duration `yaml:"reload-interval,omitempty"`
+	AllowKeyRotation bool          `yaml:"allow-key-rotation,omitempty"`
+	RotationGrace    time.Duration `yaml:"rotation-grace,omitempty"`
+
+	// Clients sending LockoutAfter invalid keys are locked out for LockoutDuration,
+	// doubled for every further invalid key up to MaxLockout
+	LockoutAfter    int           `yaml:"lockout-after,omitempty"`
+	LockoutDuration time.Duration `yaml:"lockout-duration,omitempty"`
+	MaxLockout      time.Duration `yaml:"max-lockout,omitempty"`
+	// LockoutExemptCIDRs are never locked out; DenyCIDRs are always rejected
+	LockoutExemptCIDRs []string `yaml:"lockout-exempt-cidrs,omitempty"`
+	DenyCIDRs          []string `yaml:"deny-cidrs,omitempty"`
+}
+
+// LimitConfig protects the server from clients calling it too often
+type LimitConfig struct {
+	// Requests per second allowed for each API key and each client address; 0 means unlimited
+	KeyRate   float64 `yaml:"key-rate,omitempty"`
+	KeyBurst  int     `yaml:"key-burst,omitempty"`
+	PeerRate  float64 `yaml:"peer-rate,omitempty"`
+	PeerBurst int     `yaml:"peer-burst,omitempty"`
+	// MaxConcurrentDetections caps the detectors running at the same time
+	MaxConcurrentDetections int `yaml:"max-concurrent-detections,omitempty"`
+}
+
+// DetectorConfig selects the runtimes detected by the server
+type DetectorConfig struct {
+	// Enabled lists the only detectors to run; empty means all
+	Enabled  []string `yaml:"enabled,omitempty"`
+	Disabled []string `yaml:"disabled,omitempty"`
+	// Timeout bounds each version command (e.g. java -version)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// CacheConfig keeps detection results for a while instead of re-running detectors on every call
+type CacheConfig struct {
+	TTL time.Duration `yaml:"ttl,omitempty"`
+	// Refresh re-runs detection in the background before results expire
+	Refresh bool `yaml:"refresh,omitempty"`
+}
+
+// MetricsConfig enables the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Listen string `yaml:"listen,omitempty"`
+}
+
+// LoggingConfig configures the server log
+type LoggingConfig struct {
+	Level  string `yaml:"level,omitempty"`  // debug, info, warn or error
+	Format string `yaml:"format,omitempty"` // text or json
+	File   string `yaml:"file,omitempty"`   // stderr if empty
+}
+
+// AuditConfig records every call as a JSON line
+type AuditConfig struct {
+	File string `yaml:"file,omitempty"` // "-" for stdout
+	// MaxSizeMB rotates the file once it reaches this size; MaxBackups rotated files are kept
+	MaxSizeMB  int `yaml:"max-size-mb,omitempty"`
+	MaxBackups int `yaml:"max-backups,omitempty"`
+}
+
+// Notification defines where drift notifications are sent and which events trigger them
+type Notification struct {
+	Name    string            `yaml:"name"`
+	Type    string            `yaml:"type"` // webhook, slack or command
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Command string            `yaml:"command,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"`
+	// Retries is how often a failed delivery is retried (default 2)
+	Retries *int `yaml:"retries,omitempty"`
+
+	// Triggers; empty means all
+	Events   []string `yaml:"events,omitempty"`   // runtime_changed, status_changed
+	Runtimes []string `yaml:"runtimes,omitempty"` // runtime names
+	Selector string   `yaml:"selector,omitempty"` // label selector of hosts, e.g. env=prod
+}
+
+// LoadServer reads a wsctl configuration file and applies WATCHER_* environment overrides.
+// A missing file yields a configuration from the environment alone unless required is true.
+func LoadServer(path string, required bool) (*ServerConfig, error) {
+	cfg := &ServerConfig{set: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) || required {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	} else {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if err := doc.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if len(doc.Content) > 0 {
+			collectKeys(doc.Content[0], "", cfg.set)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), EnvPrefix[:len(EnvPrefix)-1], "", cfg.set); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// collectKeys records the dotted keys of every setting in a yaml mapping
+func collectKeys(node *yaml.Node, prefix string, set map[string]bool) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		set[key] = true
+		collectKeys(node.Content[i+1], key+".", set)
+	}
+}
+
+// EnvNames lists the environment variables that override settings, in file order
+func EnvNames() []string {
+	var names []string
+	collectEnvNames(reflect.TypeOf(ServerConfig{}), EnvPrefix[:len(EnvPrefix)-1], &names)
+	return names
+}
+
+func collectEnvNames(t reflect.Type, prefix string, names *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := envName(prefix, field)
+		if name == "" {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			collectEnvNames(field.Type, name, names)
+		} else if settable(field.Type) {
+			*names = append(*names, name)
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides the fields of v with the environment variables named after their
+// yaml keys, and records the dotted keys it set in set
+func applyEnv(v reflect.Value, prefix, keyPrefix string, set map[string]bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := envName(prefix, field)
+		if name == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		key = keyPrefix + key
+
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			if err := applyEnv(v.Field(i), name, key+".", set); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok || !settable(field.Type) {
+			continue
+		}
+		if err := setFromEnv(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		set[key] = true
+	}
+	return nil
+}
+
+func envName(prefix string, field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if key == "" || key == "-" {
+		return ""
+	}
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// settable reports whether a field can be set from one environment variable
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// setFromEnv parses value into v; lists are comma-separated and maps are key=value pairs
+func setFromEnv(v reflect.Value, value string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		items := splitList(value)
+		v.Set(reflect.ValueOf(items))
+	case v.Kind() == reflect.Map:
+		m, err := labels.Parse(splitList(value))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(m))
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks the settings that can be checked without touching the system
+func (c *ServerConfig) Validate() error {
+	_, isSocket := strings.CutPrefix(c.Listen, "unix:")
+	if c.Listen != "" && !isSocket {
+		if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+	}
+
+	if c.Socket.Mode != "" {
+		if _, err := strconv.ParseUint(c.Socket.Mode, 8, 32); err != nil {
+			return fmt.Errorf("socket.mode: invalid octal mode %q", c.Socket.Mode)
+		}
+	}
+	if len(c.Socket.TrustUIDs) > 0 && !isSocket {
+		return fmt.Errorf("socket.trust-uids: requires a unix:// listen address")
+	}
+
+	for _, addr := range []struct{ name, value string }{
+		{"http.listen", c.HTTP.Listen},
+		{"metrics.listen", c.Metrics.Listen},
+	} {
+		if addr.value == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr.value); err != nil {
+			return fmt.Errorf("%s: %w", addr.name, err)
+		}
+	}
+
+	if c.TLS.Enabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			return fmt.Errorf("tls: cert-file and key-file must be set together")
+		}
+		if isSocket {
+			return fmt.Errorf("tls: not supported on a Unix socket")
+		}
+	} else if c.TLS.ClientCAFile != "" {
+		return fmt.Errorf("tls.client-ca-file: requires cert-file and key-file")
+	}
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"auth.reload-interval", c.Auth.ReloadInterval},
+		{"auth.rotation-grace", c.Auth.RotationGrace},
+		{"auth.lockout-duration", c.Auth.LockoutDuration},
+		{"auth.max-lockout", c.Auth.MaxLockout},
+		{"auth.signature-skew", c.Auth.SignatureSkew},
+		{"detectors.timeout", c.Detectors.Timeout},
+		{"cache.ttl", c.Cache.TTL},
+		{"watch-interval", c.WatchInterval},
+		{"min-watch-interval", c.MinWatchInterval},
+		{"shutdown-timeout", c.ShutdownTimeout},
+	} {
+		if d.value < 0 {
+			return fmt.Errorf("%s: must not be negative", d.name)
+		}
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value float64
+	}{
+		{"auth.lockout-after", float64(c.Auth.LockoutAfter)},
+		{"limits.key-rate", c.Limits.KeyRate},
+		{"limits.key-burst", float64(c.Limits.KeyBurst)},
+		{"limits.peer-rate", c.Limits.PeerRate},
+		{"limits.peer-burst", float64(c.Limits.PeerBurst)},
+		{"limits.max-concurrent-detections", float64(c.Limits.MaxConcurrentDetections)},
+		{"audit.max-size-mb", float64(c.Audit.MaxSizeMB)},
+		{"audit.max-backups", float64(c.Audit.MaxBackups)},
+	} {
+		if setting.value < 0 {
+			return fmt.Errorf("%s: must not be negative", setting.name)
+		}
+	}
+
+	switch c.Auth.Mode {
+	case "", AuthModeAPIKey, AuthModeHMAC, AuthModeAny:
+	default:
+		return fmt.Errorf("auth.mode: unknown mode %q (expected %s, %s or %s)", c.Auth.Mode, AuthModeAPIKey, AuthModeHMAC, AuthModeAny)
+	}
+	if c.Auth.Disabled && c.Auth.Mode != "" && c.Auth.Mode != AuthModeAPIKey {
+		return fmt.Errorf("auth.mode: %s requires authentication", c.Auth.Mode)
+	}
+
+	if c.Cache.Refresh && c.Cache.TTL <= 0 {
+		return fmt.Errorf("cache.refresh: requires cache.ttl")
+	}
+
+	for key := range c.Labels {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("labels: empty label key")
+		}
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("logging.level: unknown level %q (expected debug, info, warn or error)", c.Logging.Level)
+	}
+
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("logging.format: unknown format %q (expected text or json)", c.Logging.Format)
+	}
+
+	return nil
+}
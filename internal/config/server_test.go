@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "wsctl.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnvOverrides(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+		key   string
+		get   func(c *ServerConfig) interface{}
+		want  interface{}
+	}{
+		{"WATCHER_LISTEN", "unix:///run/watcher.sock", "listen", func(c *ServerConfig) interface{} { return c.Listen }, "unix:///run/watcher.sock"},
+		{"WATCHER_AUTH_RELOAD_INTERVAL", "0s", "auth.reload-interval", func(c *ServerConfig) interface{} { return c.Auth.ReloadInterval }, time.Duration(0)},
+		{"WATCHER_AUTH_ROTATION_GRACE", "1h30m", "auth.rotation-grace", func(c *ServerConfig) interface{} { return c.Auth.RotationGrace }, 90 * time.Minute},
+		{"WATCHER_AUTH_DISABLED", "true", "auth.disabled", func(c *ServerConfig) interface{} { return c.Auth.Disabled }, true},
+		{"WATCHER_AUTH_LOCKOUT_AFTER", "3", "auth.lockout-after", func(c *ServerConfig) interface{} { return c.Auth.LockoutAfter }, 3},
+		{"WATCHER_AUTH_DENY_CIDRS", "10.0.0.0/8, 192.0.2.0/24,", "auth.deny-cidrs", func(c *ServerConfig) interface{} { return c.Auth.DenyCIDRs }, []string{"10.0.0.0/8", "192.0.2.0/24"}},
+		{"WATCHER_LIMITS_KEY_RATE", "2.5", "limits.key-rate", func(c *ServerConfig) interface{} { return c.Limits.KeyRate }, 2.5},
+		{"WATCHER_LABELS", "env=prod,role=web", "labels", func(c *ServerConfig) interface{} { return c.Labels }, map[string]string{"env": "prod", "role": "web"}},
+		{"WATCHER_AUDIT_MAX_SIZE_MB", "0", "audit.max-size-mb", func(c *ServerConfig) interface{} { return c.Audit.MaxSizeMB }, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+
+			cfg, err := LoadServer(filepath.Join(t.TempDir(), "missing.yaml"), false)
+			if err != nil {
+				t.Fatalf("LoadServer() error = %v", err)
+			}
+			if got := tt.get(cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s=%q gives %#v, want %#v", tt.env, tt.value, got, tt.want)
+			}
+			if !cfg.IsSet(tt.key) {
+				t.Errorf("IsSet(%q) = false after setting %s", tt.key, tt.env)
+			}
+		})
+	}
+}
+
+func TestEnvOverrideErrors(t *testing.T) {
+	tests := []struct {
+		env, value string
+	}{
+		{"WATCHER_AUTH_RELOAD_INTERVAL", "soon"},
+		{"WATCHER_AUTH_DISABLED", "maybe"},
+		{"WATCHER_AUTH_LOCKOUT_AFTER", "three"},
+		{"WATCHER_LIMITS_KEY_RATE", "fast"},
+		{"WATCHER_LABELS", "env"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+
+			_, err := LoadServer(filepath.Join(t.TempDir(), "missing.yaml"), false)
+			if err == nil || !strings.Contains(err.Error(), tt.env) {
+				t.Errorf("LoadServer() error = %v, want an error naming %s", err, tt.env)
+			}
+		})
+	}
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, `
+auth:
+  reload-interval: 5s
+  rotation-grace: 0s
+cache:
+  ttl: 30s
+`)
+	t.Setenv("WATCHER_CACHE_TTL", "1m")
+
+	cfg, err := LoadServer(path, true)
+	if err != nil {
+		t.Fatalf("LoadServer() error = %v", err)
+	}
+
+	if cfg.Cache.TTL != time.Minute {
+		t.Errorf("cache.ttl = %s, want the environment's 1m", cfg.Cache.TTL)
+	}
+	if cfg.Auth.ReloadInterval != 5*time.Second {
+		t.Errorf("auth.reload-interval = %s, want 5s", cfg.Auth.ReloadInterval)
+	}
+
+	for key, want := range map[string]bool{
+		"auth":                 true,
+		"auth.reload-interval": true,
+		"auth.rotation-grace":  true, // 0도 설정된 것으로 봄
+		"cache.ttl":            true,
+		"auth.lockout-after":   false,
+		"watch-interval":       false,
+	} {
+		if got := cfg.IsSet(key); got != want {
+			t.Errorf("IsSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLoadServerFiles(t *testing.T) {
+	if _, err := LoadServer(writeConfig(t, ""), true); err != nil {
+		t.Errorf("LoadServer() of an empty file error = %v", err)
+	}
+	if _, err := LoadServer(writeConfig(t, "auth: [\n"), true); err == nil {
+		t.Error("LoadServer() accepted invalid YAML")
+	}
+	if _, err := LoadServer(filepath.Join(t.TempDir(), "missing.yaml"), true); err == nil {
+		t.Error("LoadServer() accepted a missing required file")
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	names := EnvNames()
+
+	for _, want := range []string{
+		"WATCHER_LISTEN",
+		"WATCHER_SOCKET_TRUST_UIDS",
+		"WATCHER_AUTH_RELOAD_INTERVAL",
+		"WATCHER_LIMITS_MAX_CONCURRENT_DETECTIONS",
+		"WATCHER_LABELS",
+		"WATCHER_MIN_WATCH_INTERVAL",
+	} {
+		if !slices.Contains(names, want) {
+			t.Errorf("EnvNames() is missing %s", want)
+		}
+	}
+
+	// 목록 형태의 알림 설정은 환경 변수로 덮어쓸 수 없음
+	for _, name := range names {
+		if strings.HasPrefix(name, "WATCHER_NOTIFICATIONS") {
+			t.Errorf("EnvNames() lists %s", name)
+		}
+	}
+}
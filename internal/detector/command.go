@@ -0,0 +1,34 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// DefaultTimeout bounds each version command so that a hung binary can't stall detection
+const DefaultTimeout = 10 * time.Second
+
+var commandTimeout = DefaultTimeout
+
+// SetTimeout changes how long a version command may run (0 removes the limit)
+func SetTimeout(timeout time.Duration) {
+	commandTimeout = timeout
+}
+
+// combinedOutput runs a version command and returns its stdout and stderr
+func combinedOutput(name string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if commandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+	}
+
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, fmt.Errorf("timed out after %s", commandTimeout)
+	}
+	return output, err
+}
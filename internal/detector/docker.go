@@ -26,8 +26,7 @@ func (d *DockerDetector) Detect() (*Runtime, error) {
 	runtime.Path = dockerPath
 	runtime.Found = true
 
-	cmd := exec.Command("docker", "--version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput("docker", "--version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute docker --version: %w", err)
 	}
@@ -26,8 +26,7 @@ func (d *GoDetector) Detect() (*Runtime, error) {
 	runtime.Path = goPath
 	runtime.Found = true
 
-	cmd := exec.Command("go", "version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput("go", "version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute go version: %w", err)
 	}
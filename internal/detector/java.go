@@ -32,8 +32,7 @@ func (d *JavaDetector) Detect() (*Runtime, error) {
 	runtime.Found = true
 
 	// 2. java -version 실행
-	cmd := exec.Command("java", "-version")
-	output, err := cmd.CombinedOutput() // stderr로 출력되므로 CombinedOutput 사용
+	output, err := combinedOutput("java", "-version") // stderr로 출력되므로 CombinedOutput 사용
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute java -version: %w", err)
 	}
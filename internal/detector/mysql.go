@@ -31,8 +31,7 @@ func (d *MySQLDetector) Detect() (*Runtime, error) {
 	runtime.Path = mysqlPath
 	runtime.Found = true
 
-	cmd := exec.Command(mysqlPath, "--version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(mysqlPath, "--version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute mysql --version: %w", err)
 	}
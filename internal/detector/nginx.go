@@ -26,8 +26,7 @@ func (d *NginxDetector) Detect() (*Runtime, error) {
 	runtime.Path = nginxPath
 	runtime.Found = true
 
-	cmd := exec.Command("nginx", "-v")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput("nginx", "-v")
 	if err != nil {
 		// nginx -v outputs to stderr even on success
 		if len(output) == 0 {
@@ -26,8 +26,7 @@ func (d *NodeDetector) Detect() (*Runtime, error) {
 	runtime.Path = nodePath
 	runtime.Found = true
 
-	cmd := exec.Command("node", "--version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput("node", "--version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute node --version: %w", err)
 	}
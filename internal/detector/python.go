@@ -33,8 +33,7 @@ func (d *PythonDetector) Detect() (*Runtime, error) {
 	runtime.Path = pythonPath
 	runtime.Found = true
 
-	cmd := exec.Command(pythonCmd, "--version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(pythonCmd, "--version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute python --version: %w", err)
 	}
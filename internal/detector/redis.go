@@ -30,8 +30,7 @@ func (d *RedisDetector) Detect() (*Runtime, error) {
 	runtime.Path = redisPath
 	runtime.Found = true
 
-	cmd := exec.Command(redisPath, "--version")
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(redisPath, "--version")
 	if err != nil {
 		return runtime, fmt.Errorf("failed to execute redis --version: %w", err)
 	}
@@ -1,5 +1,7 @@
 package detector
 
+import "fmt"
+
 // GetAllDetectors returns all available detectors
 func GetAllDetectors() []Detector {
 	return []Detector{
@@ -13,3 +15,38 @@ func GetAllDetectors() []Detector {
 		&NginxDetector{},
 	}
 }
+
+// Select returns the detectors named in enabled (all if empty) minus those in disabled.
+// Unknown names are an error so that typos in the configuration don't go unnoticed.
+func Select(enabled, disabled []string) ([]Detector, error) {
+	all := GetAllDetectors()
+
+	known := make(map[string]bool, len(all))
+	for _, det := range all {
+		known[det.Name()] = true
+	}
+	for _, name := range append(append([]string(nil), enabled...), disabled...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown detector %q", name)
+		}
+	}
+
+	include := toSet(enabled)
+	exclude := toSet(disabled)
+
+	var selected []Detector
+	for _, det := range all {
+		if (len(include) == 0 || include[det.Name()]) && !exclude[det.Name()] {
+			selected = append(selected, det)
+		}
+	}
+	return selected, nil
+}
+
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
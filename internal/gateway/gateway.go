@@ -0,0 +1,281 @@
+// Package gateway serves the WatcherService as HTTP/JSON for clients that don't speak gRPC
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/grpcserver"
+	"github.com/binaryarc/watcher/internal/ratelimit"
+	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Gateway translates HTTP requests into WatcherService calls
+type Gateway struct {
+	server       *grpcserver.WatcherServer
+	interceptors []grpc.UnaryServerInterceptor
+}
+
+// New returns the HTTP API of server:
+//
+//	GET /v1/runtimes?filter=java,node   detected runtimes (like ObserveRuntimes; &refresh=true
+//	                                    bypasses the server's detection cache)
+//	GET /v1/system                      host information
+//	GET /v1/info                        server information (like GetServerInfo)
+//	GET /healthz                        liveness, without authentication
+//
+// Each request passes through interceptors (e.g. metrics and auth) as if it were the
+// corresponding gRPC call, so API keys (sent as the X-API-Key header) and their scopes
+// work the same way. /v1/system is authorized as ObserveRuntimes, and /healthz as a
+// gRPC health check, so denied addresses are rejected there too.
+func New(server *grpcserver.WatcherServer, interceptors ...grpc.UnaryServerInterceptor) http.Handler {
+	g := &Gateway{server: server, interceptors: interceptors}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/runtimes", g.runtimes)
+	mux.HandleFunc("GET /v1/system", g.system)
+	mux.HandleFunc("GET /v1/info", g.info)
+	mux.HandleFunc("GET /healthz", g.healthz)
+	return mux
+}
+
+// WithGRPC serves gRPC requests with grpcServer and everything else with handler,
+// so that both can share a port. The http.Server must accept HTTP/2, which is
+// unencrypted unless it serves TLS.
+func WithGRPC(grpcServer *grpc.Server, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// NewServer returns an http.Server for handler that also accepts HTTP/2 without TLS,
+// as gRPC clients use it when TLS is not configured
+func NewServer(handler http.Handler) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &http.Server{
+		Handler:           handler,
+		Protocols:         protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+type runtimeJSON struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+type systemJSON struct {
+	Hostname string            `json:"hostname"`
+	OS       string            `json:"os"`
+	Kernel   string            `json:"kernel"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type runtimesJSON struct {
+	Runtimes        []runtimeJSON `json:"runtimes"`
+	System          systemJSON    `json:"system"`
+	Timestamp       int64         `json:"timestamp"`
+	CacheAgeSeconds int64         `json:"cache_age_seconds"`
+}
+
+type infoJSON struct {
+	Version       string            `json:"version"`
+	APIVersion    string            `json:"api_version"`
+	Detectors     []string          `json:"detectors"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	AuthMode      string            `json:"auth_mode"`
+	Hostname      string            `json:"hostname"`
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func (g *Gateway) runtimes(w http.ResponseWriter, r *http.Request) {
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+	req := &proto.ObserveRequest{RuntimeFilter: runtimeFilter(r), ForceRefresh: refresh}
+
+	resp, err := g.invoke(r, proto.WatcherService_ObserveRuntimes_FullMethodName, req,
+		func(ctx context.Context, req any) (any, error) {
+			return g.server.ObserveRuntimes(ctx, req.(*proto.ObserveRequest))
+		})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	observation := resp.(*proto.ObserveResponse)
+	result := runtimesJSON{
+		Runtimes:        make([]runtimeJSON, 0, len(observation.Runtimes)),
+		System:          newSystemJSON(observation.SystemInfo),
+		Timestamp:       observation.Timestamp,
+		CacheAgeSeconds: observation.CacheAgeSeconds,
+	}
+	for _, rt := range observation.Runtimes {
+		if rt.Found {
+			result.Runtimes = append(result.Runtimes, runtimeJSON{Name: rt.Name, Version: rt.Version, Path: rt.Path})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (g *Gateway) system(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.invoke(r, proto.WatcherService_ObserveRuntimes_FullMethodName, &proto.ObserveRequest{},
+		func(ctx context.Context, req any) (any, error) {
+			return g.server.SystemInfo(), nil
+		})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newSystemJSON(resp.(*proto.SystemInfo)))
+}
+
+func (g *Gateway) info(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.invoke(r, proto.WatcherService_GetServerInfo_FullMethodName, &proto.ServerInfoRequest{},
+		func(ctx context.Context, req any) (any, error) {
+			return g.server.GetServerInfo(ctx, req.(*proto.ServerInfoRequest))
+		})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	info := resp.(*proto.ServerInfoResponse)
+	writeJSON(w, http.StatusOK, infoJSON{
+		Version:       info.Version,
+		APIVersion:    info.ApiVersion,
+		Detectors:     info.Detectors,
+		Labels:        info.Labels,
+		UptimeSeconds: info.UptimeSeconds,
+		AuthMode:      info.AuthMode,
+		Hostname:      info.Hostname,
+	})
+}
+
+func (g *Gateway) healthz(w http.ResponseWriter, r *http.Request) {
+	_, err := g.invoke(r, healthpb.Health_Check_FullMethodName, &healthpb.HealthCheckRequest{},
+		func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// invoke runs handler behind the interceptors, with the request headers as incoming
+// gRPC metadata (X-API-Key becomes x-api-key) and the client address as the peer
+func (g *Gateway) invoke(r *http.Request, fullMethod string, req any, handler grpc.UnaryHandler) (any, error) {
+	md := metadata.MD{}
+	for name, values := range r.Header {
+		md.Append(strings.ToLower(name), values...)
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+
+	info := &grpc.UnaryServerInfo{Server: g.server, FullMethod: fullMethod}
+	for i := len(g.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := g.interceptors[i], handler
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// runtimeFilter accepts both ?filter=java,node and ?filter=java&filter=node
+func runtimeFilter(r *http.Request) []string {
+	var filter []string
+	for _, value := range r.URL.Query()["filter"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				filter = append(filter, name)
+			}
+		}
+	}
+	return filter
+}
+
+func newSystemJSON(info *proto.SystemInfo) systemJSON {
+	if info == nil {
+		return systemJSON{}
+	}
+	return systemJSON{
+		Hostname: info.Hostname,
+		OS:       info.Os,
+		Kernel:   info.Kernel,
+		Labels:   info.Labels,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with the HTTP status matching the gRPC status of err, and with
+// Retry-After when a rate limit suggests a delay
+func writeError(w http.ResponseWriter, err error) {
+	if delay, ok := ratelimit.RetryDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	}
+
+	st := status.Convert(err)
+	writeJSON(w, httpStatus(st.Code()), errorJSON{Error: st.Message(), Code: st.Code().String()})
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
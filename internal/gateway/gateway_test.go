@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/grpcserver"
+	"github.com/binaryarc/watcher/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// staticDetector always finds version 1.0 of its runtime
+type staticDetector string
+
+func (d staticDetector) Name() string { return string(d) }
+
+func (d staticDetector) Detect() (*detector.Runtime, error) {
+	return &detector.Runtime{Name: string(d), Version: "1.0", Path: "/usr/bin/" + string(d), Found: true}, nil
+}
+
+// scopedKeys validates the keys it holds and reports their scopes
+type scopedKeys map[string][]string
+
+func (k scopedKeys) Validate(key string) bool {
+	_, ok := k[key]
+	return ok
+}
+
+func (k scopedKeys) Scopes(key string) []string {
+	return k[key]
+}
+
+// newTestGateway serves the gateway of a server detecting java and go behind interceptors
+func newTestGateway(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) *httptest.Server {
+	t.Helper()
+	server := grpcserver.NewWatcherServer(grpcserver.WithDetectors([]detector.Detector{staticDetector("java"), staticDetector("go")}))
+	ts := httptest.NewServer(New(server, interceptors...))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// get requests path with key (none if empty) and returns the response with its body closed
+func get(t *testing.T, ts *httptest.Server, path, key string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: invalid JSON body: %v", path, err)
+	}
+	return resp, body
+}
+
+func TestGatewayAuth(t *testing.T) {
+	keys := scopedKeys{
+		"full": nil,
+		"java": {"runtime:java"},
+		"info": {"rpc:GetServerInfo"},
+	}
+	ts := newTestGateway(t, auth.UnaryServerInterceptor(keys))
+
+	tests := []struct {
+		name         string
+		path         string
+		key          string
+		wantStatus   int
+		wantRuntimes []string
+	}{
+		{name: "missing key", path: "/v1/runtimes", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", path: "/v1/runtimes", key: "guess", wantStatus: http.StatusForbidden},
+		{name: "valid key", path: "/v1/runtimes", key: "full", wantStatus: http.StatusOK, wantRuntimes: []string{"java", "go"}},
+		{name: "runtime scope", path: "/v1/runtimes", key: "java", wantStatus: http.StatusOK, wantRuntimes: []string{"java"}},
+		{name: "filter outside runtime scope", path: "/v1/runtimes?filter=go", key: "java", wantStatus: http.StatusOK, wantRuntimes: []string{}},
+		{name: "RPC scope", path: "/v1/info", key: "info", wantStatus: http.StatusOK},
+		{name: "outside RPC scope", path: "/v1/runtimes", key: "info", wantStatus: http.StatusForbidden},
+		{name: "system is authorized as ObserveRuntimes", path: "/v1/system", key: "info", wantStatus: http.StatusForbidden},
+		{name: "health without a key", path: "/healthz", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(t, ts, tt.path, tt.key)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("GET %s = %d %s, want %d", tt.path, resp.StatusCode, body, tt.wantStatus)
+			}
+			if tt.wantRuntimes == nil {
+				return
+			}
+
+			var result runtimesJSON
+			if err := json.Unmarshal(body, &result); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, rt := range result.Runtimes {
+				names = append(names, rt.Name)
+			}
+			if !slices.Equal(names, tt.wantRuntimes) {
+				t.Errorf("runtimes = %q, want %q", names, tt.wantRuntimes)
+			}
+		})
+	}
+}
+
+func TestGatewayMetadata(t *testing.T) {
+	var md metadata.MD
+	ts := newTestGateway(t, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	})
+
+	if resp, body := get(t, ts, "/v1/info", "watcher_secret"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /v1/info = %d %s", resp.StatusCode, body)
+	}
+	if got := md.Get(auth.APIKeyHeader); !slices.Equal(got, []string{"watcher_secret"}) {
+		t.Errorf("%s metadata = %q, want the X-API-Key header", auth.APIKeyHeader, got)
+	}
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{PerKey: ratelimit.Rate{Limit: 0.01, Burst: 1}})
+	ts := newTestGateway(t, auth.UnaryServerInterceptor(scopedKeys{"a": nil, "b": nil}), limiter.UnaryServerInterceptor())
+
+	if resp, body := get(t, ts, "/v1/info", "a"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("first request = %d %s", resp.StatusCode, body)
+	}
+
+	resp, body := get(t, ts, "/v1/info", "a")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d %s, want %d", resp.StatusCode, body, http.StatusTooManyRequests)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("rate limited response has no Retry-After header")
+	}
+
+	// 다른 키는 따로 제한되고 헬스 체크는 제한되지 않음
+	if resp, body := get(t, ts, "/v1/info", "b"); resp.StatusCode != http.StatusOK {
+		t.Errorf("request with another key = %d %s", resp.StatusCode, body)
+	}
+	if resp, body := get(t, ts, "/healthz", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("health check = %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestGatewayHealthDenied(t *testing.T) {
+	guard, err := auth.NewGuard(auth.GuardConfig{Deny: []string{"127.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := newTestGateway(t, auth.UnaryServerInterceptor(scopedKeys{"full": nil}, auth.WithGuard(guard)))
+
+	// 테스트 클라이언트는 127.0.0.1에서 접속하므로 거부됨
+	for _, path := range []string{"/healthz", "/v1/info"} {
+		if resp, body := get(t, ts, path, "full"); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s from a denied address = %d %s, want %d", path, resp.StatusCode, body, http.StatusForbidden)
+		}
+	}
+}
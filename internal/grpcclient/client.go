@@ -2,40 +2,78 @@ package grpcclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/binaryarc/watcher/internal/auth"
 	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/history"
 	pb "github.com/binaryarc/watcher/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // Client wraps gRPC client connection
 type Client struct {
-	conn   *grpc.ClientConn
-	client pb.WatcherServiceClient
-	apiKey string
+	conn      *grpc.ClientConn
+	client    pb.WatcherServiceClient
+	collector pb.CollectorServiceClient
+	apiKey    string
+	refresh   bool
+}
+
+// Options configures a client connection
+type Options struct {
+	APIKey string
+	// TLS enables transport security; nil means plaintext
+	TLS *tls.Config
+	// ForceRefresh asks servers to re-run detection instead of answering from their cache
+	ForceRefresh bool
+	// SignRequests sends an HMAC signature of each call instead of the API key
+	SignRequests bool
 }
 
 // NewClient creates a new gRPC client
 func NewClient(host string, apiKey string) (*Client, error) {
+	return NewClientWithOptions(host, Options{APIKey: apiKey})
+}
+
+// NewClientWithOptions creates a new gRPC client with TLS and authentication options
+func NewClientWithOptions(host string, opts Options) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, host,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	creds := insecure.NewCredentials()
+	if opts.TLS != nil {
+		creds = credentials.NewTLS(opts.TLS)
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithBlock(),
-	)
+	}
+	if opts.SignRequests {
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(auth.SigningUnaryClientInterceptor()),
+			grpc.WithChainStreamInterceptor(auth.SigningStreamClientInterceptor()),
+		)
+	}
+
+	conn, err := grpc.DialContext(ctx, host, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
 	}
 
 	return &Client{
-		conn:   conn,
-		client: pb.NewWatcherServiceClient(conn),
-		apiKey: apiKey,
+		conn:      conn,
+		client:    pb.NewWatcherServiceClient(conn),
+		collector: pb.NewCollectorServiceClient(conn),
+		apiKey:    opts.APIKey,
+		refresh:   opts.ForceRefresh,
 	}, nil
 }
 
@@ -47,8 +85,29 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// ObserveRuntimes fetches runtime information from remote server
-func (c *Client) ObserveRuntimes(ctx context.Context) ([]*detector.Runtime, error) {
+// Observation is a server's answer to ObserveRuntimes
+type Observation struct {
+	Runtimes []*detector.Runtime
+	Hostname string
+	OS       string
+	Kernel   string
+	Labels   map[string]string
+	// CacheAge is how old the server's cached detection results were
+	CacheAge time.Duration
+}
+
+// Observe fetches runtime and system information from remote server
+func (c *Client) Observe(ctx context.Context) (*Observation, error) {
+	resp, err := c.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewObservation(resp), nil
+}
+
+// Fetch returns the raw ObserveRuntimes response, e.g. to relay it to a collector
+func (c *Client) Fetch(ctx context.Context) (*pb.ObserveResponse, error) {
 	if c.apiKey != "" {
 		ctx = auth.InjectAPIKey(ctx, c.apiKey)
 	}
@@ -56,12 +115,17 @@ func (c *Client) ObserveRuntimes(ctx context.Context) ([]*detector.Runtime, erro
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	req := &pb.ObserveRequest{}
+	req := &pb.ObserveRequest{ForceRefresh: c.refresh}
 	resp, err := c.client.ObserveRuntimes(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("RPC call failed: %w", err)
 	}
 
+	return resp, nil
+}
+
+// NewObservation converts an ObserveRuntimes response, skipping runtimes that were not found
+func NewObservation(resp *pb.ObserveResponse) *Observation {
 	runtimes := make([]*detector.Runtime, 0, len(resp.Runtimes))
 	for _, protoRuntime := range resp.Runtimes {
 		if protoRuntime.Found {
@@ -74,7 +138,233 @@ func (c *Client) ObserveRuntimes(ctx context.Context) ([]*detector.Runtime, erro
 		}
 	}
 
-	return runtimes, nil
+	observation := &Observation{
+		Runtimes: runtimes,
+		CacheAge: time.Duration(resp.CacheAgeSeconds) * time.Second,
+	}
+	if info := resp.SystemInfo; info != nil {
+		observation.Hostname = info.Hostname
+		observation.OS = info.Os
+		observation.Kernel = info.Kernel
+		observation.Labels = info.Labels
+	}
+
+	return observation
+}
+
+// FleetHost is a collector's latest report for one agent
+type FleetHost struct {
+	Host        string
+	Address     string
+	Observation *Observation // nil if the agent was never observed
+	LastSeen    time.Time
+	Error       string
+}
+
+// QueryFleet asks a collector for the latest reports of hosts (all agents if empty).
+// Results are in the order of hosts.
+func (c *Client) QueryFleet(ctx context.Context, hosts []string, selector string) ([]FleetHost, error) {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.collector.QueryFleet(ctx, &pb.QueryFleetRequest{
+		Hosts:    hosts,
+		Selector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	fleet := make([]FleetHost, 0, len(resp.Hosts))
+	for _, report := range resp.Hosts {
+		host := FleetHost{
+			Host:    report.Host,
+			Address: report.Address,
+			Error:   report.Error,
+		}
+		if report.Observation != nil {
+			host.Observation = NewObservation(report.Observation)
+		}
+		if report.LastSeen > 0 {
+			host.LastSeen = time.Unix(report.LastSeen, 0)
+		}
+		fleet = append(fleet, host)
+	}
+
+	return fleet, nil
+}
+
+// History asks a collector for the runtime changes of host, oldest first.
+// If runtime is not empty only changes of that runtime are returned.
+func (c *Client) History(ctx context.Context, host, runtime string) ([]history.Change, error) {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := c.collector.GetHistory(ctx, &pb.HistoryRequest{Host: host, Runtime: runtime})
+	if err != nil {
+		return nil, fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	changes := make([]history.Change, 0, len(resp.Changes))
+	for _, change := range resp.Changes {
+		changes = append(changes, history.Change{
+			Timestamp:       change.Timestamp,
+			Runtime:         change.Runtime,
+			PreviousVersion: change.PreviousVersion,
+			Version:         change.Version,
+			Path:            change.Path,
+		})
+	}
+
+	return changes, nil
+}
+
+// ObservationStream sends observations to a collector over one PushObservations call
+type ObservationStream struct {
+	stream pb.CollectorService_PushObservationsClient
+	done   chan struct{}
+}
+
+// PushObservations opens a stream for pushing observations to a collector.
+// The stream lives until ctx is cancelled or Close is called.
+func (c *Client) PushObservations(ctx context.Context) (*ObservationStream, error) {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	stream, err := c.collector.PushObservations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	s := &ObservationStream{stream: stream, done: make(chan struct{})}
+	// 수집기는 정상일 때 스트림이 끝날 때까지 헤더를 보내지 않으므로 Header가
+	// 반환되면 수집기가 스트림을 끝낸 것 (예: 관측을 거부)
+	go func() {
+		stream.Header()
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+// Send pushes one observation for host (the observed hostname if empty)
+func (s *ObservationStream) Send(host string, observation *pb.ObserveResponse) error {
+	if err := s.stream.Send(&pb.PushRequest{Host: host, Observation: observation}); err != nil {
+		// 서버가 스트림을 끊으면 Send는 io.EOF만 반환하므로 실제 상태는 CloseAndRecv로 확인
+		if _, recvErr := s.stream.CloseAndRecv(); recvErr != nil {
+			err = recvErr
+		}
+		return fmt.Errorf("failed to push observation: %w", err)
+	}
+	return nil
+}
+
+// Done is closed when the collector ends the stream, e.g. because it rejected an
+// observation; Close then returns the reason
+func (s *ObservationStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the stream and returns how many observations the collector accepted
+func (s *ObservationStream) Close() (int, error) {
+	resp, err := s.stream.CloseAndRecv()
+	if err != nil {
+		return 0, fmt.Errorf("failed to close stream: %w", err)
+	}
+	return int(resp.Accepted), nil
+}
+
+// ObserveRuntimes fetches runtime information from remote server
+func (c *Client) ObserveRuntimes(ctx context.Context) ([]*detector.Runtime, error) {
+	observation, err := c.Observe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return observation.Runtimes, nil
+}
+
+// RotateKey asks the server to replace the client's API key with a new one.
+// It returns the new key and when the old key stops being accepted (zero if immediately).
+func (c *Client) RotateKey(ctx context.Context) (string, time.Time, error) {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.RotateKey(ctx, &pb.RotateKeyRequest{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	var expiresAt time.Time
+	if resp.OldKeyExpiresAt > 0 {
+		expiresAt = time.Unix(resp.OldKeyExpiresAt, 0)
+	}
+
+	return resp.NewKey, expiresAt, nil
+}
+
+// WatchOptions configures a WatchRuntimes call
+type WatchOptions struct {
+	RuntimeFilter  []string
+	Interval       time.Duration // server default if 0
+	IncludeInitial bool          // report runtimes found at the start as added
+}
+
+// WatchRuntimes streams runtime changes to onChange until ctx is cancelled,
+// the server ends the stream, or onChange returns an error
+func (c *Client) WatchRuntimes(ctx context.Context, opts WatchOptions, onChange func(history.Change) error) error {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	stream, err := c.client.WatchRuntimes(ctx, &pb.WatchRequest{
+		RuntimeFilter:   opts.RuntimeFilter,
+		IntervalSeconds: int32(opts.Interval / time.Second),
+		IncludeInitial:  opts.IncludeInitial,
+	})
+	if err != nil {
+		return fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("watch stream failed: %w", err)
+		}
+
+		change := history.Change{
+			Timestamp:       event.Timestamp,
+			PreviousVersion: event.PreviousVersion,
+		}
+		if rt := event.Runtime; rt != nil {
+			change.Runtime = rt.Name
+			change.Version = rt.Version
+			change.Path = rt.Path
+		}
+
+		if err := onChange(change); err != nil {
+			return err
+		}
+	}
 }
 
 // ObserveRuntime fetches specific runtime information from remote server
@@ -95,3 +385,53 @@ func (c *Client) ObserveRuntime(ctx context.Context, name string) (*detector.Run
 		Found: false,
 	}, nil
 }
+
+// CheckHealth asks the standard gRPC health service for the status of service
+// ("" for the server as a whole), e.g. "SERVING". No API key is needed.
+func (c *Client) CheckHealth(ctx context.Context, service string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return "", fmt.Errorf("health check failed: %w", err)
+	}
+
+	return resp.Status.String(), nil
+}
+
+// ServerInfo describes a watcher server
+type ServerInfo struct {
+	Version    string
+	APIVersion string
+	Detectors  []string
+	Labels     map[string]string
+	Uptime     time.Duration
+	AuthMode   string
+	Hostname   string
+}
+
+// GetServerInfo fetches the build, API version, detectors, labels, uptime and auth mode of the server
+func (c *Client) GetServerInfo(ctx context.Context) (*ServerInfo, error) {
+	if c.apiKey != "" {
+		ctx = auth.InjectAPIKey(ctx, c.apiKey)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.GetServerInfo(ctx, &pb.ServerInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("RPC call failed: %w", err)
+	}
+
+	return &ServerInfo{
+		Version:    resp.Version,
+		APIVersion: resp.ApiVersion,
+		Detectors:  resp.Detectors,
+		Labels:     resp.Labels,
+		Uptime:     time.Duration(resp.UptimeSeconds) * time.Second,
+		AuthMode:   resp.AuthMode,
+		Hostname:   resp.Hostname,
+	}, nil
+}
@@ -0,0 +1,194 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/detector"
+)
+
+// WithCacheTTL reuses each detector's result for ttl instead of re-running its version
+// command on every call (0 disables caching; concurrent calls still share one run)
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *WatcherServer) {
+		s.cache.ttl = ttl
+	}
+}
+
+// WithMaxConcurrentDetections runs at most n detectors at the same time, whether for
+// ObserveRuntimes, watch streams or cache refreshes; further runs wait for a free slot
+// until their call is cancelled (0 means unlimited)
+func WithMaxConcurrentDetections(n int) Option {
+	return func(s *WatcherServer) {
+		if n > 0 {
+			s.detecting = make(chan struct{}, n)
+		} else {
+			s.detecting = nil
+		}
+	}
+}
+
+// detectionCache holds the latest result of each detector. Calls for a missing or
+// expired result wait for a single run of the detector instead of starting their own.
+type detectionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	result   detection
+	valid    bool
+	inflight *detectionCall
+}
+
+// detection is the outcome of one detector run
+type detection struct {
+	runtime    *detector.Runtime
+	err        error
+	detectedAt time.Time
+	cached     bool // reused from an earlier call instead of run for this one
+}
+
+// errDetectorPanicked is the result of a detector run that panicked
+var errDetectorPanicked = errors.New("detector panicked")
+
+type detectionCall struct {
+	done   chan struct{}
+	result detection
+	// cancelled is set when the caller running the detector gave up before it ran
+	cancelled bool
+}
+
+// get returns the cached result of det, running it when the result is older than the TTL
+// or force is set. Forced calls join a run that is already in progress. Calls stop
+// waiting when ctx is done.
+func (c *detectionCache) get(ctx context.Context, det detector.Detector, force bool, run func(context.Context, detector.Detector) detection) detection {
+	name := det.Name()
+
+	for {
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]*cacheEntry)
+		}
+		entry := c.entries[name]
+		if entry == nil {
+			entry = &cacheEntry{}
+			c.entries[name] = entry
+		}
+
+		if call := entry.inflight; call != nil {
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return detection{err: ctx.Err(), detectedAt: time.Now()}
+			}
+			// 실행하던 호출이 취소되어 결과가 없으면 이 호출이 다시 실행
+			if call.cancelled {
+				continue
+			}
+			return call.result
+		}
+		if !force && entry.valid && time.Since(entry.result.detectedAt) < c.ttl {
+			result := entry.result
+			c.mu.Unlock()
+			result.cached = true
+			return result
+		}
+
+		call := &detectionCall{
+			done:   make(chan struct{}),
+			result: detection{err: errDetectorPanicked, detectedAt: time.Now()},
+		}
+		entry.inflight = call
+		c.mu.Unlock()
+
+		return c.run(ctx, entry, call, det, run)
+	}
+}
+
+// run runs det for call and publishes the result to the calls waiting for it
+func (c *detectionCache) run(ctx context.Context, entry *cacheEntry, call *detectionCall, det detector.Detector, run func(context.Context, detector.Detector) detection) detection {
+	// run이 panic해도 기다리는 호출들이 풀려나도록 defer로 정리
+	completed := false
+	defer func() {
+		c.mu.Lock()
+		if completed {
+			entry.result = call.result
+			entry.valid = true
+		}
+		entry.inflight = nil
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.result = run(ctx, det)
+	// 취소로 실행하지 못한 결과는 캐시하지 않고, 기다리던 호출이 다시 실행함
+	if call.result.err != nil && ctx.Err() != nil {
+		call.cancelled = true
+	} else {
+		completed = true
+	}
+
+	return call.result
+}
+
+// detect runs det through the cache and reports actual runs to the DetectionObserver.
+// A panicking detector yields errDetectorPanicked instead of crashing the server.
+func (s *WatcherServer) detect(ctx context.Context, det detector.Detector, force bool) detection {
+	return s.cache.get(ctx, det, force, func(ctx context.Context, det detector.Detector) (result detection) {
+		// 캐시된 결과는 슬롯을 쓰지 않고, 실제 실행만 동시 실행 수에 포함됨
+		if s.detecting != nil {
+			select {
+			case s.detecting <- struct{}{}:
+				defer func() { <-s.detecting }()
+			case <-ctx.Done():
+				return detection{err: ctx.Err(), detectedAt: time.Now()}
+			}
+		}
+
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				result = detection{err: fmt.Errorf("%w: %v", errDetectorPanicked, r), detectedAt: time.Now()}
+				if s.observer != nil {
+					s.observer.ObserveDetection(det.Name(), nil, time.Since(start), result.err)
+				}
+			}
+		}()
+
+		runtime, err := det.Detect()
+		if s.observer != nil {
+			s.observer.ObserveDetection(det.Name(), runtime, time.Since(start), err)
+		}
+		return detection{runtime: runtime, err: err, detectedAt: time.Now()}
+	})
+}
+
+// RefreshCache re-runs every detector in the background before its cached result expires,
+// so that calls never wait for detection, until ctx is cancelled. It does nothing without a TTL.
+func (s *WatcherServer) RefreshCache(ctx context.Context) {
+	if s.cache.ttl <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cache.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		for _, det := range s.detectors {
+			s.detect(ctx, det, true)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDetector counts its runs; release, if set, holds each run until it is closed
+type fakeDetector struct {
+	name    string
+	runs    atomic.Int32
+	release chan struct{}
+}
+
+func (d *fakeDetector) Name() string { return d.name }
+
+func (d *fakeDetector) Detect() (*detector.Runtime, error) {
+	d.runs.Add(1)
+	if d.release != nil {
+		<-d.release
+	}
+	return &detector.Runtime{Name: d.name, Version: "1.0", Found: true}, nil
+}
+
+func runDetector(ctx context.Context, det detector.Detector) detection {
+	runtime, err := det.Detect()
+	return detection{runtime: runtime, err: err, detectedAt: time.Now()}
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		wait       time.Duration
+		force      bool
+		wantRuns   int32
+		wantCached bool
+	}{
+		{name: "disabled", ttl: 0, wantRuns: 2},
+		{name: "fresh result reused", ttl: time.Minute, wantRuns: 1, wantCached: true},
+		{name: "expired result re-run", ttl: 10 * time.Millisecond, wait: 20 * time.Millisecond, wantRuns: 2},
+		{name: "forced", ttl: time.Minute, force: true, wantRuns: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &detectionCache{ttl: tt.ttl}
+			det := &fakeDetector{name: "java"}
+
+			if first := cache.get(context.Background(), det, false, runDetector); first.cached {
+				t.Error("first result is marked as cached")
+			}
+			time.Sleep(tt.wait)
+			second := cache.get(context.Background(), det, tt.force, runDetector)
+
+			if got := det.runs.Load(); got != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", got, tt.wantRuns)
+			}
+			if second.cached != tt.wantCached {
+				t.Errorf("second result cached = %v, want %v", second.cached, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestCacheSingleFlight(t *testing.T) {
+	for _, ttl := range []time.Duration{0, time.Minute} {
+		t.Run(ttl.String(), func(t *testing.T) {
+			cache := &detectionCache{ttl: ttl}
+			det := &fakeDetector{name: "java", release: make(chan struct{})}
+
+			const callers = 10
+			results := make(chan detection, callers)
+			for i := 0; i < callers; i++ {
+				// 강제 새로고침도 진행 중인 실행에 합류해야 함
+				go func(force bool) {
+					results <- cache.get(context.Background(), det, force, runDetector)
+				}(i%2 == 0)
+			}
+
+			// 모든 호출이 진행 중인 실행을 기다릴 때까지 대기
+			deadline := time.Now().Add(5 * time.Second)
+			for det.runs.Load() == 0 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(det.release)
+
+			for i := 0; i < callers; i++ {
+				if result := <-results; result.runtime == nil || result.cached {
+					t.Errorf("result = %+v, want the shared fresh run", result)
+				}
+			}
+			if got := det.runs.Load(); got != 1 {
+				t.Errorf("runs = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCachePanicReleasesWaiters(t *testing.T) {
+	cache := &detectionCache{ttl: time.Minute}
+	det := &fakeDetector{name: "java"}
+	started := make(chan struct{})
+
+	go func() {
+		defer func() { recover() }()
+		cache.get(context.Background(), det, false, func(context.Context, detector.Detector) detection {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			panic("detector bug")
+		})
+	}()
+
+	<-started
+	waiter := make(chan detection)
+	go func() { waiter <- cache.get(context.Background(), det, false, runDetector) }()
+
+	select {
+	case result := <-waiter:
+		// 진행 중이던 실행에 합류했거나, panic 뒤에 새로 실행함
+		if result.err != nil && !errors.Is(result.err, errDetectorPanicked) {
+			t.Errorf("result error = %v", result.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter is still blocked after the run panicked")
+	}
+
+	// panic한 결과는 캐시되지 않음
+	if result := cache.get(context.Background(), det, false, runDetector); result.err != nil {
+		t.Errorf("result after panic = %v, want a new run", result.err)
+	}
+}
+
+func TestObserveCacheAge(t *testing.T) {
+	det := &fakeDetector{name: "java"}
+	s := NewWatcherServer(WithDetectors([]detector.Detector{det}), WithCacheTTL(time.Hour))
+
+	first, err := s.ObserveRuntimes(context.Background(), &proto.ObserveRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CacheAgeSeconds != 0 {
+		t.Errorf("fresh result CacheAgeSeconds = %d, want 0", first.CacheAgeSeconds)
+	}
+
+	// 캐시된 결과를 오래된 것으로 만듦
+	s.cache.mu.Lock()
+	s.cache.entries["java"].result.detectedAt = time.Now().Add(-90 * time.Second)
+	s.cache.mu.Unlock()
+
+	second, err := s.ObserveRuntimes(context.Background(), &proto.ObserveRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.CacheAgeSeconds < 90 || second.CacheAgeSeconds > 91 {
+		t.Errorf("cached result CacheAgeSeconds = %d, want 90", second.CacheAgeSeconds)
+	}
+
+	forced, err := s.ObserveRuntimes(context.Background(), &proto.ObserveRequest{ForceRefresh: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forced.CacheAgeSeconds != 0 {
+		t.Errorf("forced result CacheAgeSeconds = %d, want 0", forced.CacheAgeSeconds)
+	}
+}
+
+func TestMaxConcurrentDetections(t *testing.T) {
+	release := make(chan struct{})
+	java := &fakeDetector{name: "java", release: release}
+	node := &fakeDetector{name: "node", release: release}
+	python := &fakeDetector{name: "python", release: release}
+	s := NewWatcherServer(WithDetectors([]detector.Detector{java, node}), WithMaxConcurrentDetections(2))
+
+	// 호출, watch 스트림, 캐시 새로고침이 모두 같은 슬롯을 나눠 씀
+	done := make(chan struct{}, 3)
+	go func() {
+		s.ObserveRuntimes(context.Background(), &proto.ObserveRequest{RuntimeFilter: []string{"java"}})
+		done <- struct{}{}
+	}()
+	go func() {
+		s.detect(context.Background(), node, true)
+		done <- struct{}{}
+	}()
+	go func() {
+		s.detect(context.Background(), python, true)
+		done <- struct{}{}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if runs := java.runs.Load() + node.runs.Load() + python.runs.Load(); runs != 2 {
+		t.Errorf("%d detectors running, want 2", runs)
+	}
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("detections did not finish after the detectors were released")
+		}
+	}
+	if runs := java.runs.Load() + node.runs.Load() + python.runs.Load(); runs != 3 {
+		t.Errorf("%d detectors ran, want 3", runs)
+	}
+}
+
+func TestCacheWaitCancelled(t *testing.T) {
+	cache := &detectionCache{ttl: time.Minute}
+	det := &fakeDetector{name: "java", release: make(chan struct{})}
+	defer close(det.release)
+
+	go cache.get(context.Background(), det, false, runDetector)
+	for det.runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 진행 중인 실행을 기다리던 호출은 취소되면 바로 돌아옴
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	waiter := make(chan detection)
+	go func() { waiter <- cache.get(ctx, det, false, runDetector) }()
+
+	select {
+	case result := <-waiter:
+		if !errors.Is(result.err, context.DeadlineExceeded) {
+			t.Errorf("result error = %v, want %v", result.err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter is still blocked after its context expired")
+	}
+}
+
+// panicDetector panics on every run
+type panicDetector struct{}
+
+func (panicDetector) Name() string { return "broken" }
+
+func (panicDetector) Detect() (*detector.Runtime, error) { panic("detector bug") }
+
+func TestDetectorPanic(t *testing.T) {
+	java := &fakeDetector{name: "java"}
+	s := NewWatcherServer(WithDetectors([]detector.Detector{panicDetector{}, java}))
+
+	// panic한 감지기는 건너뛰고 서버는 계속 응답함
+	for i := 0; i < 2; i++ {
+		resp, err := s.ObserveRuntimes(context.Background(), &proto.ObserveRequest{ForceRefresh: true})
+		if err != nil {
+			t.Fatalf("ObserveRuntimes() error = %v", err)
+		}
+		if len(resp.Runtimes) != 1 || resp.Runtimes[0].Name != "java" {
+			t.Errorf("ObserveRuntimes() runtimes = %v, want only java", resp.Runtimes)
+		}
+	}
+
+	if result := s.detect(context.Background(), panicDetector{}, true); !errors.Is(result.err, errDetectorPanicked) {
+		t.Errorf("detect() error = %v, want %v", result.err, errDetectorPanicked)
+	}
+}
+
+func TestDetectionSlotCancelled(t *testing.T) {
+	java := &fakeDetector{name: "java", release: make(chan struct{})}
+	node := &fakeDetector{name: "node"}
+	s := NewWatcherServer(WithDetectors([]detector.Detector{java, node}), WithMaxConcurrentDetections(1))
+
+	// java가 유일한 슬롯을 차지함
+	go s.detect(context.Background(), java, true)
+	for java.runs.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.ObserveRuntimes(ctx, &proto.ObserveRequest{RuntimeFilter: []string{"node"}}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("ObserveRuntimes() waiting for a slot = %v, want %v", err, codes.DeadlineExceeded)
+	}
+
+	// 슬롯을 기다리다 취소된 실행에 합류한 호출은 스스로 다시 실행함
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan detection)
+	go func() { leader <- s.detect(leaderCtx, node, true) }()
+	for !inflight(s, "node") {
+		time.Sleep(time.Millisecond)
+	}
+	waiter := make(chan detection)
+	go func() { waiter <- s.detect(context.Background(), node, false) }()
+	time.Sleep(20 * time.Millisecond)
+
+	cancelLeader()
+	if result := <-leader; !errors.Is(result.err, context.Canceled) {
+		t.Errorf("cancelled detect() error = %v, want %v", result.err, context.Canceled)
+	}
+	close(java.release)
+
+	select {
+	case result := <-waiter:
+		if result.err != nil || result.runtime == nil {
+			t.Errorf("detect() = %+v, want node's runtime", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("detect() did not finish after the slot was freed")
+	}
+}
+
+// inflight reports whether the named detector is being run
+func inflight(s *WatcherServer, name string) bool {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+	entry := s.cache.entries[name]
+	return entry != nil && entry.inflight != nil
+}
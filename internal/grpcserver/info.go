@@ -0,0 +1,45 @@
+package grpcserver
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/version"
+	"github.com/binaryarc/watcher/proto"
+)
+
+// Authentication modes reported by GetServerInfo
+const (
+	AuthModeAPIKey       = "api_key"
+	AuthModeHMAC         = "hmac"
+	AuthModeAPIKeyOrHMAC = "api_key_or_hmac"
+	AuthModeDisabled     = "disabled"
+)
+
+// WithAuthMode sets the authentication mode reported by GetServerInfo (AuthModeAPIKey by default)
+func WithAuthMode(mode string) Option {
+	return func(s *WatcherServer) {
+		s.authMode = mode
+	}
+}
+
+// GetServerInfo describes this server: build, API version, detectors, labels, uptime and auth mode
+func (s *WatcherServer) GetServerInfo(ctx context.Context, req *proto.ServerInfoRequest) (*proto.ServerInfoResponse, error) {
+	names := make([]string, 0, len(s.detectors))
+	for _, det := range s.detectors {
+		names = append(names, det.Name())
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &proto.ServerInfoResponse{
+		Version:       version.String(),
+		ApiVersion:    version.APIVersion,
+		Detectors:     names,
+		Labels:        s.labels,
+		UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+		AuthMode:      s.authMode,
+		Hostname:      hostname,
+	}, nil
+}
@@ -2,41 +2,131 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/binaryarc/watcher/internal/auth"
 	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/binaryarc/watcher/internal/keystore"
 	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type WatcherServer struct {
 	proto.UnimplementedWatcherServiceServer
+
+	rotator          KeyRotator
+	rotationGrace    time.Duration
+	labels           map[string]string
+	watchInterval    time.Duration
+	minWatchInterval time.Duration
+	observer         DetectionObserver
+	authMode         string
+	startedAt        time.Time
+	detectors        []detector.Detector
+	cache            detectionCache
+	detecting        chan struct{} // bounds detector runs, nil when unlimited
+}
+
+// DetectionObserver is told about every detector run, e.g. to export metrics
+type DetectionObserver interface {
+	ObserveDetection(name string, runtime *detector.Runtime, duration time.Duration, err error)
+}
+
+// KeyRotator replaces an API key with a successor that inherits its settings
+type KeyRotator interface {
+	Rotate(oldKey, newKey string, grace time.Duration) (replaced, successor *keystore.KeyInfo, err error)
 }
 
-func NewWatcherServer() *WatcherServer {
-	return &WatcherServer{}
+// Option configures a WatcherServer
+type Option func(*WatcherServer)
+
+// WithKeyRotation lets clients rotate their own API key through the RotateKey RPC.
+// The previous key stays valid for the grace period.
+func WithKeyRotation(rotator KeyRotator, grace time.Duration) Option {
+	return func(s *WatcherServer) {
+		s.rotator = rotator
+		s.rotationGrace = grace
+	}
+}
+
+// WithLabels advertises labels (e.g. env=prod) in the SystemInfo of every response
+func WithLabels(labels map[string]string) Option {
+	return func(s *WatcherServer) {
+		s.labels = labels
+	}
+}
+
+// WithDetectionObserver reports every detector run to observer
+func WithDetectionObserver(observer DetectionObserver) Option {
+	return func(s *WatcherServer) {
+		s.observer = observer
+	}
+}
+
+// WithDetectors limits detection to detectors (all detectors by default)
+func WithDetectors(detectors []detector.Detector) Option {
+	return func(s *WatcherServer) {
+		s.detectors = detectors
+	}
+}
+
+func NewWatcherServer(opts ...Option) *WatcherServer {
+	s := &WatcherServer{
+		watchInterval:    DefaultWatchInterval,
+		minWatchInterval: DefaultMinWatchInterval,
+		authMode:         AuthModeAPIKey,
+		startedAt:        time.Now(),
+		detectors:        detector.GetAllDetectors(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *WatcherServer) ObserveRuntimes(ctx context.Context, req *proto.ObserveRequest) (*proto.ObserveResponse, error) {
-	// 1. 모든 detector 가져오기
-	detectors := detector.GetAllDetectors()
+	// 1. 사용할 detector 가져오기
+	detectors := s.detectors
+
+	// 2. 필터가 있으면 적용 (키 scope로 범위를 좁힘)
+	runtimeFilter := req.RuntimeFilter
+	if scope, ok := auth.ScopeFromContext(ctx); ok {
+		narrowed, allowed := scope.NarrowRuntimes(runtimeFilter)
+		if !allowed {
+			detectors = nil
+		}
+		runtimeFilter = narrowed
+	}
 
-	// 2. 필터가 있으면 적용
-	if len(req.RuntimeFilter) > 0 {
-		detectors = filterDetectors(detectors, req.RuntimeFilter)
+	if len(runtimeFilter) > 0 {
+		detectors = filterDetectors(detectors, runtimeFilter)
 	}
 
-	// 3. 런타임 감지
-	var protoRuntimes []*proto.Runtime
+	// 3. 런타임 감지 (캐시가 유효하면 재사용)
+	// 응답 시각과 캐시 나이는 캐시에서 꺼낸 결과 중 가장 오래된 것 기준
+	var (
+		protoRuntimes []*proto.Runtime
+		oldestCached  time.Time
+	)
 	for _, det := range detectors {
-		runtime, err := det.Detect()
-		if err != nil {
+		result := s.detect(ctx, det, req.ForceRefresh)
+		if result.cached && (oldestCached.IsZero() || result.detectedAt.Before(oldestCached)) {
+			oldestCached = result.detectedAt
+		}
+		if result.err != nil {
 			continue
 		}
 
+		runtime := result.runtime
 		if runtime.Found {
 			protoRuntimes = append(protoRuntimes, &proto.Runtime{
 				Name:    runtime.Name,
@@ -47,22 +137,87 @@ func (s *WatcherServer) ObserveRuntimes(ctx context.Context, req *proto.ObserveR
 		}
 	}
 
-	// 4. 시스템 정보 수집
+	// 감지 슬롯이나 진행 중인 실행을 기다리다 취소된 호출
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	// 4. 응답 생성
+	response := &proto.ObserveResponse{
+		Runtimes:   protoRuntimes,
+		SystemInfo: s.SystemInfo(),
+		Timestamp:  time.Now().Unix(),
+	}
+	if !oldestCached.IsZero() {
+		response.Timestamp = oldestCached.Unix()
+		response.CacheAgeSeconds = int64(time.Since(oldestCached) / time.Second)
+	}
+
+	return response, nil
+}
+
+// SystemInfo describes this host without running any detector
+func (s *WatcherServer) SystemInfo() *proto.SystemInfo {
 	hostname, _ := os.Hostname()
-	systemInfo := &proto.SystemInfo{
+	return &proto.SystemInfo{
 		Hostname: hostname,
 		Os:       getOS(),
 		Kernel:   getKernel(),
+		Labels:   s.labels,
 	}
+}
 
-	// 5. 응답 생성
-	response := &proto.ObserveResponse{
-		Runtimes:   protoRuntimes,
-		SystemInfo: systemInfo,
-		Timestamp:  getCurrentTimestamp(),
+// RotateKey mints a successor for the API key used to make the call
+func (s *WatcherServer) RotateKey(ctx context.Context, req *proto.RotateKeyRequest) (*proto.RotateKeyResponse, error) {
+	if s.rotator == nil {
+		return nil, status.Error(codes.Unimplemented, "key rotation is not enabled on this server")
 	}
 
-	return response, nil
+	apiKey, err := auth.ExtractAPIKey(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "missing API key")
+	}
+
+	// 서명 방식은 키를 평문으로 보내지 않으므로, 새 키도 평문 연결로 돌려주지 않음
+	_, signed := auth.ExtractKeyID(ctx)
+	if (signed || s.authMode == AuthModeHMAC) && !secureTransport(ctx) {
+		return nil, status.Error(codes.FailedPrecondition, "rotating a key with signed requests requires TLS, since the response carries the new key")
+	}
+
+	newKey, err := keymanager.GenerateKey()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate key: %v", err)
+	}
+
+	replaced, successor, err := s.rotator.Rotate(apiKey, newKey, s.rotationGrace)
+	if errors.Is(err, keystore.ErrAlreadyRotated) {
+		return nil, status.Error(codes.FailedPrecondition, "this key was already rotated; install its successor")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to rotate key: %v", err)
+	}
+
+	var expiresAt int64
+	if replaced.ExpiresAt != nil {
+		expiresAt = replaced.ExpiresAt.Unix()
+	}
+
+	return &proto.RotateKeyResponse{
+		NewKey:          successor.Key,
+		OldKeyExpiresAt: expiresAt,
+	}, nil
+}
+
+// secureTransport reports whether the call came over TLS or a local Unix socket
+func secureTransport(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	if _, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		return true
+	}
+	return p.Addr != nil && p.Addr.Network() == "unix"
 }
 
 func filterDetectors(detectors []detector.Detector, filters []string) []detector.Detector {
@@ -91,7 +246,3 @@ func getKernel() string {
 	}
 	return strings.TrimSpace(string(out))
 }
-
-func getCurrentTimestamp() int64 {
-	return time.Now().Unix()
-}
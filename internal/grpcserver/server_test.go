@@ -0,0 +1,140 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/keystore"
+	"github.com/binaryarc/watcher/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeKeys accepts a single API key, plain or signed
+type fakeKeys string
+
+func (k fakeKeys) Validate(key string) bool { return key == string(k) }
+
+func (k fakeKeys) ResolveKeyID(id string) (string, bool) {
+	return string(k), id == auth.KeyID(string(k))
+}
+
+// fakeRotator hands out every new key it is given
+type fakeRotator struct{}
+
+func (fakeRotator) Rotate(oldKey, newKey string, grace time.Duration) (*keystore.KeyInfo, *keystore.KeyInfo, error) {
+	return &keystore.KeyInfo{Key: oldKey}, &keystore.KeyInfo{Key: newKey}, nil
+}
+
+func TestRotateKeyTransport(t *testing.T) {
+	const key = "watcher_0123456789abcdefghijklmnopqrstuv"
+	tcp := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 10), Port: 40000}
+
+	tests := []struct {
+		name     string
+		mode     string
+		signed   bool
+		addr     net.Addr
+		authInfo credentials.AuthInfo
+		wantCode codes.Code
+	}{
+		{name: "plain key over plaintext", mode: AuthModeAPIKey, addr: tcp, wantCode: codes.OK},
+		{name: "signed over plaintext", mode: AuthModeAPIKeyOrHMAC, signed: true, addr: tcp, wantCode: codes.FailedPrecondition},
+		{name: "hmac mode over plaintext", mode: AuthModeHMAC, signed: true, addr: tcp, wantCode: codes.FailedPrecondition},
+		{name: "hmac mode over TLS", mode: AuthModeHMAC, signed: true, addr: tcp, authInfo: credentials.TLSInfo{}, wantCode: codes.OK},
+		{name: "hmac mode over a Unix socket", mode: AuthModeHMAC, signed: true, addr: &net.UnixAddr{Name: "/run/watcher.sock", Net: "unix"}, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewWatcherServer(WithDetectors(nil), WithAuthMode(tt.mode), WithKeyRotation(fakeRotator{}, time.Hour))
+
+			out := auth.InjectAPIKey(context.Background(), key)
+			if tt.signed {
+				var err error
+				if out, err = auth.InjectSignature(out, key, proto.WatcherService_RotateKey_FullMethodName); err != nil {
+					t.Fatal(err)
+				}
+			}
+			md, _ := metadata.FromOutgoingContext(out)
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: tt.addr, AuthInfo: tt.authInfo})
+
+			// 서명은 인증 인터셉터가 검증함
+			interceptor := auth.UnaryServerInterceptor(fakeKeys(key), auth.WithSignatures(auth.NewSignatureVerifier(fakeKeys(key), 0), false))
+			info := &grpc.UnaryServerInfo{FullMethod: proto.WatcherService_RotateKey_FullMethodName}
+			_, err := interceptor(ctx, &proto.RotateKeyRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return s.RotateKey(ctx, req.(*proto.RotateKeyRequest))
+			})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("RotateKey() = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestScopedRuntimes(t *testing.T) {
+	dets := []detector.Detector{&fakeDetector{name: "java"}, &fakeDetector{name: "go"}, &fakeDetector{name: "python"}}
+	s := NewWatcherServer(WithDetectors(dets))
+
+	tests := []struct {
+		name   string
+		scope  *auth.Scope // nil for calls without a key
+		filter []string
+		want   []string
+	}{
+		{name: "no key", want: []string{"java", "go", "python"}},
+		{name: "unrestricted key", scope: &auth.Scope{}, filter: []string{"go"}, want: []string{"go"}},
+		{name: "scoped key", scope: &auth.Scope{Runtimes: []string{"java", "python"}}, want: []string{"java", "python"}},
+		{name: "filter narrowed by scope", scope: &auth.Scope{Runtimes: []string{"java"}}, filter: []string{"java", "go"}, want: []string{"java"}},
+		{name: "filter outside scope", scope: &auth.Scope{Runtimes: []string{"java"}}, filter: []string{"go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.scope != nil {
+				ctx = auth.WithScope(ctx, *tt.scope)
+			}
+
+			resp, err := s.ObserveRuntimes(ctx, &proto.ObserveRequest{RuntimeFilter: tt.filter})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var observed []string
+			for _, rt := range resp.Runtimes {
+				observed = append(observed, rt.Name)
+			}
+			if !slices.Equal(observed, tt.want) {
+				t.Errorf("ObserveRuntimes() runtimes = %q, want %q", observed, tt.want)
+			}
+
+			// Watch는 같은 scope로 처음 발견한 런타임을 보고함
+			ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			var watched []string
+			s.Watch(ctx, tt.filter, time.Hour, true, func(changes []history.Change) error {
+				for _, change := range changes {
+					watched = append(watched, change.Runtime)
+				}
+				cancel()
+				return nil
+			})
+			cancel()
+			slices.Sort(watched)
+			want := slices.Sorted(slices.Values(tt.want))
+			if !slices.Equal(watched, want) {
+				t.Errorf("Watch() runtimes = %q, want %q", watched, want)
+			}
+		})
+	}
+}
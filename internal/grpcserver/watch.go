@@ -0,0 +1,173 @@
+package grpcserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/proto"
+)
+
+const (
+	// DefaultWatchInterval is how often WatchRuntimes re-runs detection
+	DefaultWatchInterval = 30 * time.Second
+
+	// DefaultMinWatchInterval is the shortest interval clients may ask for, so that
+	// streams can't keep the detectors busy
+	DefaultMinWatchInterval = 5 * time.Second
+
+	// 설치/업그레이드를 빨리 감지하기 위해 PATH 디렉터리와 바이너리 mtime은 더 자주 확인
+	watchCheckInterval = 2 * time.Second
+)
+
+// WithWatchInterval sets how often WatchRuntimes re-runs detection when clients don't ask for an interval
+func WithWatchInterval(interval time.Duration) Option {
+	return func(s *WatcherServer) {
+		s.watchInterval = interval
+	}
+}
+
+// WithMinWatchInterval sets the shortest interval clients may ask WatchRuntimes for.
+// Shorter intervals are raised to it.
+func WithMinWatchInterval(interval time.Duration) Option {
+	return func(s *WatcherServer) {
+		s.minWatchInterval = interval
+	}
+}
+
+// WatchRuntimes streams runtime changes. Detection is re-run periodically and as soon
+// as a directory in PATH or a detected binary changes on disk.
+func (s *WatcherServer) WatchRuntimes(req *proto.WatchRequest, stream proto.WatcherService_WatchRuntimesServer) error {
+	interval := s.watchInterval
+	if req.IntervalSeconds > 0 {
+		interval = max(time.Duration(req.IntervalSeconds)*time.Second, s.minWatchInterval)
+	}
+
+	return s.Watch(stream.Context(), req.RuntimeFilter, interval, req.IncludeInitial, func(changes []history.Change) error {
+		return sendChanges(stream, changes)
+	})
+}
+
+// Watch calls onChange with the runtime changes of this host until ctx is cancelled or
+// onChange returns an error. With initial, the runtimes found at the start are reported as added.
+func (s *WatcherServer) Watch(ctx context.Context, runtimeFilter []string, interval time.Duration, initial bool, onChange func([]history.Change) error) error {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	// 바이너리가 바뀐 경우에는 캐시된 결과를 쓰지 않음
+	observe := func(force bool) ([]history.Runtime, error) {
+		resp, err := s.ObserveRuntimes(ctx, &proto.ObserveRequest{RuntimeFilter: runtimeFilter, ForceRefresh: force})
+		if err != nil {
+			return nil, err
+		}
+		return history.FromProto(resp.Runtimes), nil
+	}
+
+	current, err := observe(false)
+	if err != nil {
+		return err
+	}
+
+	if initial {
+		if changes := history.Diff(nil, current); len(changes) > 0 {
+			if err := onChange(changes); err != nil {
+				return err
+			}
+		}
+	}
+
+	fingerprint := binaryFingerprint(current)
+	lastDetection := time.Now()
+
+	ticker := time.NewTicker(min(watchCheckInterval, interval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		latest := binaryFingerprint(current)
+		if latest == fingerprint && time.Since(lastDetection) < interval {
+			continue
+		}
+
+		next, err := observe(latest != fingerprint)
+		if err != nil {
+			return err
+		}
+		lastDetection = time.Now()
+
+		if changes := history.Diff(current, next); len(changes) > 0 {
+			if err := onChange(changes); err != nil {
+				return err
+			}
+		}
+
+		current = next
+		fingerprint = binaryFingerprint(current)
+	}
+}
+
+func sendChanges(stream proto.WatcherService_WatchRuntimesServer, changes []history.Change) error {
+	now := time.Now().Unix()
+
+	for _, change := range changes {
+		event := &proto.RuntimeEvent{
+			Runtime: &proto.Runtime{
+				Name:    change.Runtime,
+				Version: change.Version,
+				Path:    change.Path,
+				Found:   change.Kind() != history.Removed,
+			},
+			PreviousVersion: change.PreviousVersion,
+			Timestamp:       now,
+		}
+
+		switch change.Kind() {
+		case history.Added:
+			event.Type = proto.RuntimeEvent_ADDED
+		case history.Removed:
+			event.Type = proto.RuntimeEvent_REMOVED
+		default:
+			event.Type = proto.RuntimeEvent_CHANGED
+		}
+
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// binaryFingerprint summarizes the modification times of the PATH directories and of
+// the detected binaries, so installs, upgrades and removals can be noticed cheaply
+func binaryFingerprint(runtimes []history.Runtime) string {
+	var b strings.Builder
+
+	paths := filepath.SplitList(os.Getenv("PATH"))
+	for _, rt := range runtimes {
+		if rt.Path != "" {
+			paths = append(paths, rt.Path)
+		}
+	}
+
+	for _, path := range paths {
+		b.WriteString(path)
+		b.WriteByte('=')
+		if info, err := os.Stat(path); err == nil {
+			b.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		}
+		b.WriteByte(';')
+	}
+
+	return b.String()
+}
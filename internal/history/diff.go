@@ -0,0 +1,94 @@
+package history
+
+import (
+	"sort"
+
+	"github.com/binaryarc/watcher/proto"
+)
+
+// Change kinds
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Runtime is the part of an observed runtime that is tracked over time
+type Runtime struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Change is a runtime that appeared, disappeared, or changed version or path
+type Change struct {
+	Timestamp       int64
+	Runtime         string
+	PreviousVersion string
+	Version         string
+	Path            string
+}
+
+// Kind returns Added, Removed or Changed
+func (c Change) Kind() string {
+	switch {
+	case c.PreviousVersion == "":
+		return Added
+	case c.Version == "":
+		return Removed
+	default:
+		return Changed
+	}
+}
+
+// FromProto converts the found runtimes of an observation, sorted by name
+func FromProto(runtimes []*proto.Runtime) []Runtime {
+	result := make([]Runtime, 0, len(runtimes))
+	for _, rt := range runtimes {
+		if rt.Found {
+			result = append(result, Runtime{Name: rt.Name, Version: rt.Version, Path: rt.Path})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// Diff returns the changes between two observations, sorted by runtime name
+func Diff(before, after []Runtime) []Change {
+	previous := make(map[string]Runtime, len(before))
+	for _, rt := range before {
+		previous[rt.Name] = rt
+	}
+
+	current := make(map[string]Runtime, len(after))
+	for _, rt := range after {
+		current[rt.Name] = rt
+	}
+
+	var changes []Change
+	for name, rt := range current {
+		old, existed := previous[name]
+		switch {
+		case !existed:
+			changes = append(changes, Change{Runtime: name, Version: rt.Version, Path: rt.Path})
+		case old.Version != rt.Version || old.Path != rt.Path:
+			changes = append(changes, Change{Runtime: name, PreviousVersion: old.Version, Version: rt.Version, Path: rt.Path})
+		}
+	}
+
+	for name, old := range previous {
+		if _, exists := current[name]; !exists {
+			changes = append(changes, Change{Runtime: name, PreviousVersion: old.Version, Path: old.Path})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Runtime < changes[j].Runtime
+	})
+
+	return changes
+}
@@ -0,0 +1,280 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/proto"
+)
+
+// Entry is one distinct observation of a host, as stored in the log
+type Entry struct {
+	Host      string    `json:"host"`
+	Timestamp int64     `json:"timestamp"`
+	Runtimes  []Runtime `json:"runtimes"`
+}
+
+// compactInterval is how often Record drops entries older than the retention
+const compactInterval = time.Hour
+
+// Store is an append-only log of observations. An entry is only written when a
+// host's runtimes differ from its previous entry, so the log stays small.
+// Entries are also kept in memory per host, so queries don't read the file.
+type Store struct {
+	mu          sync.RWMutex
+	path        string
+	file        *os.File
+	hosts       map[string][]Entry
+	retention   time.Duration
+	compactedAt time.Time
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithRetention drops entries older than retention. The last entry of a host
+// before the cutoff is kept, so its current runtimes are never forgotten.
+func WithRetention(retention time.Duration) Option {
+	return func(s *Store) {
+		s.retention = retention
+	}
+}
+
+// Open opens or creates the history log at path
+func Open(path string, opts ...Option) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	s := &Store{
+		path:  path,
+		hosts: make(map[string][]Entry),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	err := s.scan(func(entry Entry) {
+		s.hosts[entry.Host] = append(s.hosts[entry.Host], entry)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if s.retention > 0 {
+		if err := s.compact(time.Now()); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	s.file = file
+
+	// 쓰기 도중 중단되어 줄바꿈 없이 끝난 경우 다음 항목이 붙지 않도록 줄을 끊음
+	if err := s.terminateLastLine(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close closes the log file
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// Record appends an observation of host if its runtimes changed since the last entry.
+// It returns true if an entry was written.
+func (s *Store) Record(host string, observation *proto.ObserveResponse) (bool, error) {
+	runtimes := FromProto(observation.Runtimes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entries := s.hosts[host]; len(entries) > 0 && len(Diff(entries[len(entries)-1].Runtimes, runtimes)) == 0 {
+		return false, nil
+	}
+
+	timestamp := observation.Timestamp
+	if timestamp == 0 {
+		timestamp = time.Now().Unix()
+	}
+
+	entry := Entry{Host: host, Timestamp: timestamp, Runtimes: runtimes}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return false, fmt.Errorf("failed to write history entry: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return false, fmt.Errorf("failed to write history entry: %w", err)
+	}
+
+	s.hosts[host] = append(s.hosts[host], entry)
+
+	if now := time.Now(); s.retention > 0 && now.Sub(s.compactedAt) > compactInterval {
+		if err := s.compact(now); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// Changes returns the timeline of runtime changes of host, oldest first.
+// If runtime is not empty only changes of that runtime are returned.
+func (s *Store) Changes(host, runtime string) ([]Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		changes  []Change
+		previous []Runtime
+	)
+
+	for _, entry := range s.hosts[host] {
+		for _, change := range Diff(previous, entry.Runtimes) {
+			if runtime != "" && change.Runtime != runtime {
+				continue
+			}
+			change.Timestamp = entry.Timestamp
+			changes = append(changes, change)
+		}
+		previous = entry.Runtimes
+	}
+
+	return changes, nil
+}
+
+// compact drops entries older than the retention and rewrites the log with the
+// remaining ones. The caller must hold the write lock.
+func (s *Store) compact(now time.Time) error {
+	cutoff := now.Add(-s.retention).Unix()
+
+	names := make([]string, 0, len(s.hosts))
+	for host, entries := range s.hosts {
+		// 기준 시점의 상태를 알 수 있도록 cutoff 이전의 마지막 항목은 남김
+		keep := 0
+		for keep < len(entries)-1 && entries[keep+1].Timestamp < cutoff {
+			keep++
+		}
+		s.hosts[host] = append([]Entry(nil), entries[keep:]...)
+		names = append(names, host)
+	}
+	sort.Strings(names)
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to compact history file: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, host := range names {
+		for _, entry := range s.hosts[host] {
+			if err = encoder.Encode(entry); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, s.path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compact history file: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.compactedAt = now
+
+	return nil
+}
+
+// terminateLastLine appends a newline if the log doesn't end with one
+func (s *Store) terminateLastLine() error {
+	info, err := s.file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	reader, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	last := make([]byte, 1)
+	if _, err := reader.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if last[0] != '\n' {
+		if _, err := s.file.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("failed to write history file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scan calls fn for every entry of the log. A truncated last line, e.g. from a
+// crash during a write, is skipped.
+func (s *Store) scan(fn func(Entry)) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		fn(entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return nil
+}
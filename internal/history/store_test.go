@@ -0,0 +1,148 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/binaryarc/watcher/proto"
+)
+
+func observation(timestamp int64, versions ...string) *proto.ObserveResponse {
+	resp := &proto.ObserveResponse{Timestamp: timestamp}
+	for i := 0; i+1 < len(versions); i += 2 {
+		resp.Runtimes = append(resp.Runtimes, &proto.Runtime{Name: versions[i], Version: versions[i+1], Found: true})
+	}
+	return resp
+}
+
+func TestRecordAndChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []struct {
+		host        string
+		observation *proto.ObserveResponse
+		written     bool
+	}{
+		{"web", observation(100, "java", "17", "go", "1.21"), true},
+		{"web", observation(200, "java", "17", "go", "1.21"), false},
+		{"db", observation(250, "java", "11"), true},
+		{"web", observation(300, "java", "21", "go", "1.21"), true},
+		{"web", observation(400, "java", "21"), true},
+	}
+	for _, r := range records {
+		written, err := s.Record(r.host, r.observation)
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if written != r.written {
+			t.Errorf("Record(%s, %d) = %v, want %v", r.host, r.observation.Timestamp, written, r.written)
+		}
+	}
+	s.Close()
+
+	// 다시 열어도 같은 결과가 나와야 함
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		host, runtime string
+		want          []Change
+	}{
+		{"web", "java", []Change{
+			{Timestamp: 100, Runtime: "java", Version: "17"},
+			{Timestamp: 300, Runtime: "java", PreviousVersion: "17", Version: "21"},
+		}},
+		{"web", "go", []Change{
+			{Timestamp: 100, Runtime: "go", Version: "1.21"},
+			{Timestamp: 400, Runtime: "go", PreviousVersion: "1.21"},
+		}},
+		{"db", "", []Change{{Timestamp: 250, Runtime: "java", Version: "11"}}},
+		{"unknown", "", nil},
+	}
+	for _, tt := range tests {
+		got, err := s.Changes(tt.host, tt.runtime)
+		if err != nil {
+			t.Fatalf("Changes() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Changes(%q, %q) = %+v, want %+v", tt.host, tt.runtime, got, tt.want)
+		}
+	}
+
+	if written, _ := s.Record("web", observation(500, "java", "21")); written {
+		t.Error("Record() wrote an unchanged observation after reopening")
+	}
+}
+
+func TestRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := int64(24 * time.Hour / time.Second)
+	now := time.Now().Unix()
+	for _, o := range []*proto.ObserveResponse{
+		observation(now-10*day, "java", "11"),
+		observation(now-5*day, "java", "17"),
+		observation(now-1*day, "java", "21"),
+	} {
+		if _, err := s.Record("web", o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Record("db", observation(now-10*day, "go", "1.20")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = Open(path, WithRetention(3*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		host string
+		want []Change
+	}{
+		// 10일 전 항목은 지워지고 cutoff 직전의 17이 기준으로 남음
+		{"web", []Change{
+			{Timestamp: now - 5*day, Runtime: "java", Version: "17"},
+			{Timestamp: now - 1*day, Runtime: "java", PreviousVersion: "17", Version: "21"},
+		}},
+		// 변경이 없는 호스트의 마지막 상태는 유지됨
+		{"db", []Change{{Timestamp: now - 10*day, Runtime: "go", Version: "1.20"}}},
+	}
+	for _, tt := range tests {
+		got, _ := s.Changes(tt.host, "")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Changes(%q) = %+v, want %+v", tt.host, got, tt.want)
+		}
+	}
+
+	// 압축된 파일에 이어서 기록할 수 있어야 함
+	if _, err := s.Record("web", observation(now, "java", "22")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, _ := s.Changes("web", ""); len(got) != 3 {
+		t.Errorf("Changes() after compaction = %+v, want 3 changes", got)
+	}
+}
@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hostRangePattern = regexp.MustCompile(`\[([0-9a-zA-Z]+):([0-9a-zA-Z]+)\]`)
+
+// maxHostRangeHosts caps the hosts one range pattern may expand to, so that a typo such
+// as web[0:99999999] fails instead of exhausting memory
+const maxHostRangeHosts = 10000
+
+// parseINI parses an Ansible INI inventory, including [group:vars] and [group:children] sections
+func parseINI(content string) (*Inventory, error) {
+	b := newBuilder()
+
+	section := "ungrouped"
+	kind := "hosts"
+
+	for lineNo, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			header := strings.TrimSpace(line[1 : len(line)-1])
+			section, kind = header, "hosts"
+			if idx := strings.Index(header, ":"); idx >= 0 {
+				section, kind = header[:idx], header[idx+1:]
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return nil, fmt.Errorf("line %d: unknown section type %q", lineNo+1, kind)
+			}
+			b.addGroup(section)
+			continue
+		}
+
+		switch kind {
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value", lineNo+1)
+			}
+			b.setGroupVar(section, strings.TrimSpace(key), unquote(strings.TrimSpace(value)))
+		case "children":
+			b.addChild(section, strings.Fields(line)[0])
+		default:
+			fields := splitFields(line)
+			vars := make(map[string]string)
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNo+1, field)
+				}
+				vars[key] = unquote(value)
+			}
+
+			names, err := expandHostRange(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+			}
+			for _, name := range names {
+				b.addHost(name, section, vars)
+			}
+		}
+	}
+
+	return b.build(), nil
+}
+
+// expandHostRange expands Ansible host ranges such as web[01:03].example.com or db-[a:c]
+func expandHostRange(pattern string) ([]string, error) {
+	loc := hostRangePattern.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+
+	prefix, suffix := pattern[:loc[0]], pattern[loc[1]:]
+	start, end := pattern[loc[2]:loc[3]], pattern[loc[4]:loc[5]]
+
+	rest, err := expandHostRange(suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	if from, err := strconv.Atoi(start); err == nil {
+		to, err := strconv.Atoi(end)
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid host range %q", pattern)
+		}
+		if to-from >= maxHostRangeHosts {
+			return nil, fmt.Errorf("host range %q expands to more than %d hosts", pattern, maxHostRangeHosts)
+		}
+		width := 0
+		if len(start) > 1 && start[0] == '0' {
+			width = len(start)
+		}
+		for i := from; i <= to; i++ {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+		}
+	} else {
+		if len(start) != 1 || len(end) != 1 || end[0] < start[0] {
+			return nil, fmt.Errorf("invalid host range %q", pattern)
+		}
+		for c := start[0]; c <= end[0]; c++ {
+			values = append(values, string(c))
+		}
+	}
+
+	if len(values)*len(rest) > maxHostRangeHosts {
+		return nil, fmt.Errorf("host range %q expands to more than %d hosts", pattern, maxHostRangeHosts)
+	}
+
+	hosts := make([]string, 0, len(values)*len(rest))
+	for _, value := range values {
+		for _, tail := range rest {
+			hosts = append(hosts, prefix+value+tail)
+		}
+	}
+
+	return hosts, nil
+}
+
+// splitFields splits on whitespace while keeping quoted values together
+func splitFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
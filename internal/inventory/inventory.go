@@ -0,0 +1,334 @@
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPort is used for hosts without a watcher_port variable
+	DefaultPort = 9090
+
+	// PortVar is the host or group variable holding the watcher server port
+	PortVar = "watcher_port"
+	// AddressVar overrides the address used to reach the watcher server
+	AddressVar = "watcher_host"
+)
+
+// Host is a single inventory entry
+type Host struct {
+	Name    string
+	Address string // ansible_host / HostName; empty means Name
+	Groups  []string
+	Vars    map[string]string
+}
+
+// Inventory is an ordered list of hosts and the groups they belong to
+type Inventory struct {
+	Hosts []*Host
+}
+
+// Load reads an inventory file, detecting its format:
+// Ansible YAML (.yml/.yaml), SSH config (Host entries), Ansible INI ([group] sections) or a plain host list
+func Load(filePath string) (*Inventory, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	content := string(data)
+
+	var inv *Inventory
+	switch {
+	case strings.HasSuffix(filePath, ".yml") || strings.HasSuffix(filePath, ".yaml"):
+		inv, err = parseYAML(data)
+	case isSSHConfig(filePath, content):
+		inv, err = parseSSHConfig(content)
+	case isINI(content):
+		inv, err = parseINI(content)
+	default:
+		inv, err = parseList(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse inventory %s: %w", filePath, err)
+	}
+
+	return inv, nil
+}
+
+// Target returns the address:port of the watcher server on the host
+func (h *Host) Target() string {
+	address := h.Address
+	if v, ok := h.Vars[AddressVar]; ok && v != "" {
+		address = v
+	}
+	if address == "" {
+		address = h.Name
+	}
+
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+
+	port := strconv.Itoa(DefaultPort)
+	if v, ok := h.Vars[PortVar]; ok && v != "" {
+		port = v
+	}
+
+	return net.JoinHostPort(address, port)
+}
+
+// InGroup reports whether the host is a member of the group
+func (h *Host) InGroup(group string) bool {
+	if group == "all" {
+		return true
+	}
+	for _, g := range h.Groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the hosts matching an Ansible-style limit pattern.
+// Terms are separated by "," or ":" and may be group names or host globs;
+// "!term" excludes and "&term" intersects. An empty limit selects all hosts.
+func (inv *Inventory) Select(limit string) ([]*Host, error) {
+	limit = strings.TrimSpace(limit)
+	if limit == "" || limit == "all" || limit == "*" {
+		return inv.Hosts, nil
+	}
+
+	terms := strings.FieldsFunc(limit, func(r rune) bool {
+		return r == ',' || r == ':'
+	})
+
+	var include, intersect, exclude []string
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		switch {
+		case term == "":
+			continue
+		case strings.HasPrefix(term, "!"):
+			exclude = append(exclude, term[1:])
+		case strings.HasPrefix(term, "&"):
+			intersect = append(intersect, term[1:])
+		default:
+			include = append(include, term)
+		}
+		if _, err := path.Match(strings.TrimLeft(term, "!&"), ""); err != nil {
+			return nil, fmt.Errorf("invalid limit pattern %q: %w", term, err)
+		}
+	}
+
+	var selected []*Host
+	for _, host := range inv.Hosts {
+		if len(include) > 0 && !matchesAny(host, include) {
+			continue
+		}
+		if !matchesAll(host, intersect) {
+			continue
+		}
+		if matchesAny(host, exclude) {
+			continue
+		}
+		selected = append(selected, host)
+	}
+
+	return selected, nil
+}
+
+func matchesAny(host *Host, terms []string) bool {
+	for _, term := range terms {
+		if matches(host, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAll(host *Host, terms []string) bool {
+	for _, term := range terms {
+		if !matches(host, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(host *Host, term string) bool {
+	if host.InGroup(term) {
+		return true
+	}
+	if ok, _ := path.Match(term, host.Name); ok {
+		return true
+	}
+	for _, group := range host.Groups {
+		if ok, _ := path.Match(term, group); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// builder accumulates groups while parsing and resolves nested membership and variables
+type builder struct {
+	order     []string
+	hosts     map[string]*Host
+	hostVars  map[string]map[string]string
+	groupVars map[string]map[string]string
+	members   map[string][]string // group -> hosts
+	children  map[string][]string // group -> child groups
+	groups    []string
+}
+
+func newBuilder() *builder {
+	return &builder{
+		hosts:     make(map[string]*Host),
+		hostVars:  make(map[string]map[string]string),
+		groupVars: make(map[string]map[string]string),
+		members:   make(map[string][]string),
+		children:  make(map[string][]string),
+	}
+}
+
+func (b *builder) addGroup(group string) {
+	if _, ok := b.groupVars[group]; ok {
+		return
+	}
+	b.groupVars[group] = make(map[string]string)
+	b.groups = append(b.groups, group)
+}
+
+func (b *builder) addHost(name, group string, vars map[string]string) {
+	if _, ok := b.hosts[name]; !ok {
+		b.hosts[name] = &Host{Name: name}
+		b.hostVars[name] = make(map[string]string)
+		b.order = append(b.order, name)
+	}
+
+	for k, v := range vars {
+		b.hostVars[name][k] = v
+	}
+
+	if group != "" {
+		b.addGroup(group)
+		b.members[group] = append(b.members[group], name)
+	}
+}
+
+func (b *builder) addChild(parent, child string) {
+	b.addGroup(parent)
+	b.addGroup(child)
+	b.children[parent] = append(b.children[parent], child)
+}
+
+func (b *builder) setGroupVar(group, key, value string) {
+	b.addGroup(group)
+	b.groupVars[group][key] = value
+}
+
+// build resolves nested groups; group variables apply from the outermost group inward
+// and host variables take precedence over all of them
+func (b *builder) build() *Inventory {
+	parents := make(map[string][]string)
+	for parent, children := range b.children {
+		for _, child := range children {
+			parents[child] = append(parents[child], parent)
+		}
+	}
+
+	inv := &Inventory{}
+	for _, name := range b.order {
+		host := b.hosts[name]
+
+		direct := []string{}
+		for _, group := range b.groups {
+			for _, member := range b.members[group] {
+				if member == name {
+					direct = append(direct, group)
+					break
+				}
+			}
+		}
+
+		seen := make(map[string]bool)
+		var ordered []string // ancestors before descendants
+		var visit func(group string)
+		visit = func(group string) {
+			if seen[group] {
+				return
+			}
+			seen[group] = true
+			for _, parent := range parents[group] {
+				visit(parent)
+			}
+			ordered = append(ordered, group)
+		}
+		for _, group := range direct {
+			visit(group)
+		}
+
+		host.Vars = make(map[string]string)
+		for k, v := range b.groupVars["all"] {
+			host.Vars[k] = v
+		}
+		for _, group := range ordered {
+			if group == "all" {
+				continue
+			}
+			host.Groups = append(host.Groups, group)
+			for k, v := range b.groupVars[group] {
+				host.Vars[k] = v
+			}
+		}
+		for k, v := range b.hostVars[name] {
+			host.Vars[k] = v
+		}
+
+		if v, ok := host.Vars["ansible_host"]; ok && host.Address == "" {
+			host.Address = v
+		}
+
+		inv.Hosts = append(inv.Hosts, host)
+	}
+
+	return inv
+}
+
+func isSSHConfig(filePath, content string) bool {
+	if filepath.Base(filePath) == "config" && filepath.Base(filepath.Dir(filePath)) == ".ssh" {
+		return true
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		return strings.EqualFold(fields[0], "Host") || strings.EqualFold(fields[0], "Match")
+	}
+
+	return false
+}
+
+func isINI(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			return true
+		}
+		// 변수(key=value)가 있는 호스트 라인도 INI 형식
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") && strings.Contains(line, "=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandHostRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+		wantErr string
+	}{
+		{pattern: "web1", want: []string{"web1"}},
+		{pattern: "web[1:3]", want: []string{"web1", "web2", "web3"}},
+		{pattern: "web[01:03].example.com", want: []string{"web01.example.com", "web02.example.com", "web03.example.com"}},
+		{pattern: "db-[a:c]", want: []string{"db-a", "db-b", "db-c"}},
+		{pattern: "r[1:2]n[a:b]", want: []string{"r1na", "r1nb", "r2na", "r2nb"}},
+		{pattern: "web[3:1]", wantErr: "invalid host range"},
+		{pattern: "web[a:3]", wantErr: "invalid host range"},
+		{pattern: "db-[aa:cc]", wantErr: "invalid host range"},
+		{pattern: "web[0:99999999]", wantErr: "more than 10000 hosts"},
+		{pattern: "r[1:200]n[1:200]", wantErr: "more than 10000 hosts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := expandHostRange(tt.pattern)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandHostRange(%q) error = %v, want %q", tt.pattern, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandHostRange(%q) error = %v", tt.pattern, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandHostRange(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+// summary is what the tests compare for each parsed host
+type summary struct {
+	Name   string
+	Target string
+	Groups []string
+}
+
+func summarize(inv *Inventory) []summary {
+	var hosts []summary
+	for _, host := range inv.Hosts {
+		hosts = append(hosts, summary{Name: host.Name, Target: host.Target(), Groups: host.Groups})
+	}
+	return hosts
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []summary
+	}{
+		{
+			name: "ini",
+			file: "hosts.ini",
+			content: `# comment
+[web]
+web[1:2] watcher_port=9191
+[db]
+db1 ansible_host=10.0.0.5
+[prod:children]
+web
+[prod:vars]
+watcher_port=9292
+`,
+			want: []summary{
+				{Name: "web1", Target: "web1:9191", Groups: []string{"prod", "web"}},
+				{Name: "web2", Target: "web2:9191", Groups: []string{"prod", "web"}},
+				{Name: "db1", Target: "10.0.0.5:9090", Groups: []string{"db"}},
+			},
+		},
+		{
+			name: "ini group vars",
+			file: "hosts",
+			content: `[web]
+web1
+[web:vars]
+watcher_host=192.0.2.1
+`,
+			want: []summary{
+				{Name: "web1", Target: "192.0.2.1:9090", Groups: []string{"web"}},
+			},
+		},
+		{
+			name: "yaml",
+			file: "hosts.yml",
+			content: `all:
+  children:
+    web:
+      hosts:
+        web-12: { ansible_host: 10.0.0.12, watcher_port: 9191 }
+        web-[1:2]:
+      vars: { env: prod }
+`,
+			want: []summary{
+				{Name: "web-12", Target: "10.0.0.12:9191", Groups: []string{"web"}},
+				{Name: "web-1", Target: "web-1:9090", Groups: []string{"web"}},
+				{Name: "web-2", Target: "web-2:9090", Groups: []string{"web"}},
+			},
+		},
+		{
+			name: "ssh config",
+			file: "ssh_config",
+			content: `Host *
+  User admin
+Host bastion jump
+  HostName 192.0.2.10
+Host !skip web
+  HostName=web.internal
+Match host foo
+  HostName ignored
+`,
+			want: []summary{
+				{Name: "bastion", Target: "192.0.2.10:9090"},
+				{Name: "jump", Target: "192.0.2.10:9090"},
+				{Name: "web", Target: "web.internal:9090"},
+			},
+		},
+		{
+			name: "plain list",
+			file: "hosts.txt",
+			content: `web1 web2:9191 # trailing comment
+db1,db2
+`,
+			want: []summary{
+				{Name: "web1", Target: "web1:9090"},
+				{Name: "web2:9191", Target: "web2:9191"},
+				{Name: "db1", Target: "db1:9090"},
+				{Name: "db2", Target: "db2:9090"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			inv, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := summarize(inv); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() hosts =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseINIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown section", content: "[web:other]\n", wantErr: "unknown section type"},
+		{name: "bad group var", content: "[web:vars]\nport\n", wantErr: "expected key=value"},
+		{name: "bad host var", content: "[web]\nweb1 port\n", wantErr: "expected key=value"},
+		{name: "huge range", content: "[web]\nweb[0:99999999]\n", wantErr: "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseINI(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseINI() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	inv, err := parseINI(`[web]
+web-01
+web-02
+[db]
+db-01
+[prod]
+web-01
+db-01
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		limit string
+		want  []string
+	}{
+		{limit: "", want: []string{"web-01", "web-02", "db-01"}},
+		{limit: "all", want: []string{"web-01", "web-02", "db-01"}},
+		{limit: "web", want: []string{"web-01", "web-02"}},
+		{limit: "web:&prod", want: []string{"web-01"}},
+		{limit: "web:!web-01", want: []string{"web-02"}},
+		{limit: "db-*,web-02", want: []string{"web-02", "db-01"}},
+		{limit: "nothing", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			hosts, err := inv.Select(tt.limit)
+			if err != nil {
+				t.Fatalf("Select(%q) error = %v", tt.limit, err)
+			}
+			var got []string
+			for _, host := range hosts {
+				got = append(got, host.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select(%q) = %v, want %v", tt.limit, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := inv.Select("web[1"); err == nil {
+		t.Error("Select() accepted an invalid pattern")
+	}
+}
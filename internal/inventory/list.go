@@ -0,0 +1,23 @@
+package inventory
+
+import "strings"
+
+// parseList reads one host (optionally host:port) per line; "#" starts a comment
+func parseList(content string) (*Inventory, error) {
+	b := newBuilder()
+
+	for _, raw := range strings.Split(content, "\n") {
+		line := raw
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		for _, host := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		}) {
+			b.addHost(host, "", nil)
+		}
+	}
+
+	return b.build(), nil
+}
@@ -0,0 +1,55 @@
+package inventory
+
+import (
+	"strings"
+)
+
+// parseSSHConfig turns ssh_config Host entries into hosts. Wildcard and negated
+// patterns and Match blocks are skipped; HostName becomes the host address.
+func parseSSHConfig(content string) (*Inventory, error) {
+	b := newBuilder()
+
+	var current []string
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := splitSSHLine(line)
+		switch strings.ToLower(keyword) {
+		case "host":
+			current = nil
+			for _, alias := range strings.Fields(value) {
+				if strings.ContainsAny(alias, "*?!") {
+					continue
+				}
+				b.addHost(alias, "", nil)
+				current = append(current, alias)
+			}
+		case "match":
+			current = nil
+		case "hostname":
+			for _, alias := range current {
+				if b.hosts[alias].Address == "" {
+					b.hosts[alias].Address = value
+				}
+			}
+		}
+	}
+
+	return b.build(), nil
+}
+
+// splitSSHLine splits "Keyword value" or "Keyword=value"
+func splitSSHLine(line string) (string, string) {
+	idx := strings.IndexAny(line, " \t=")
+	if idx < 0 {
+		return line, ""
+	}
+
+	value := strings.TrimSpace(line[idx+1:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+
+	return line[:idx], unquote(value)
+}
@@ -0,0 +1,122 @@
+package inventory
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML parses an Ansible YAML inventory:
+//
+//	all:
+//	  children:
+//	    web:
+//	      hosts:
+//	        web-12: { ansible_host: 10.0.0.12, watcher_port: 9191 }
+//	      vars: { env: prod }
+func parseYAML(data []byte) (*Inventory, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder()
+	if len(root.Content) == 0 {
+		return b.build(), nil
+	}
+
+	top := root.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping of groups")
+	}
+
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if err := parseYAMLGroup(b, top.Content[i].Value, top.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.build(), nil
+}
+
+func parseYAMLGroup(b *builder, group string, node *yaml.Node) error {
+	b.addGroup(group)
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("group %q: expected a mapping", group)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		switch key {
+		case "hosts":
+			if value.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				vars, err := scalarMap(value.Content[j+1])
+				if err != nil {
+					return fmt.Errorf("host %q: %w", value.Content[j].Value, err)
+				}
+				names, err := expandHostRange(value.Content[j].Value)
+				if err != nil {
+					return err
+				}
+				for _, name := range names {
+					b.addHost(name, group, vars)
+				}
+			}
+		case "vars":
+			vars, err := scalarMap(value)
+			if err != nil {
+				return fmt.Errorf("group %q vars: %w", group, err)
+			}
+			for k, v := range vars {
+				b.setGroupVar(group, k, v)
+			}
+		case "children":
+			if value.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				child := value.Content[j].Value
+				b.addChild(group, child)
+				if err := parseYAMLGroup(b, child, value.Content[j+1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// scalarMap converts a mapping of variables to strings; nested values are kept as YAML text
+func scalarMap(node *yaml.Node) (map[string]string, error) {
+	vars := make(map[string]string)
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return vars, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping")
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if value.Kind == yaml.ScalarNode {
+			vars[node.Content[i].Value] = value.Value
+			continue
+		}
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		vars[node.Content[i].Value] = string(out)
+	}
+
+	return vars, nil
+}
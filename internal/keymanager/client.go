@@ -3,15 +3,22 @@ package keymanager
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
 const (
 	KeyPrefix      = "watcher_"
 	KeyLength      = 32
 	DefaultKeyName = "default"
+
+	currentFile  = ".current"
+	bindingsFile = ".bindings.json"
 )
 
 // Manager handles client-side API key management
@@ -19,6 +26,12 @@ type Manager struct {
 	keysDir string
 }
 
+// Binding maps a host pattern (glob, e.g. "prod-*") to a saved key name
+type Binding struct {
+	Pattern string `json:"pattern"`
+	Key     string `json:"key"`
+}
+
 // NewManager creates a new key manager
 func NewManager(keysDir string) (*Manager, error) {
 	if err := os.MkdirAll(keysDir, 0700); err != nil {
@@ -32,6 +45,11 @@ func NewManager(keysDir string) (*Manager, error) {
 
 // Generate creates a new API key
 func (m *Manager) Generate() (string, error) {
+	return GenerateKey()
+}
+
+// GenerateKey creates a new random API key with the watcher_ prefix
+func GenerateKey() (string, error) {
 	randomBytes := make([]byte, KeyLength)
 	if _, err := rand.Read(randomBytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %w", err)
@@ -45,6 +63,10 @@ func (m *Manager) Generate() (string, error) {
 
 // Save stores an API key with the given name
 func (m *Manager) Save(name, apiKey string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
 	keyPath := filepath.Join(m.keysDir, name)
 
 	if err := os.WriteFile(keyPath, []byte(apiKey), 0600); err != nil {
@@ -56,6 +78,10 @@ func (m *Manager) Save(name, apiKey string) error {
 
 // Load reads an API key by name
 func (m *Manager) Load(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+
 	keyPath := filepath.Join(m.keysDir, name)
 
 	data, err := os.ReadFile(keyPath)
@@ -66,7 +92,7 @@ func (m *Manager) Load(name string) (string, error) {
 		return "", fmt.Errorf("failed to load key: %w", err)
 	}
 
-	return string(data), nil
+	return strings.TrimSpace(string(data)), nil
 }
 
 // List returns all saved key names
@@ -81,7 +107,8 @@ func (m *Manager) List() ([]string, error) {
 
 	var keys []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// 설정 파일(.current, .bindings.json)은 키가 아님
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 			keys = append(keys, entry.Name())
 		}
 	}
@@ -91,6 +118,10 @@ func (m *Manager) List() ([]string, error) {
 
 // Delete removes a saved key
 func (m *Manager) Delete(name string) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
 	keyPath := filepath.Join(m.keysDir, name)
 
 	if err := os.Remove(keyPath); err != nil {
@@ -100,10 +131,157 @@ func (m *Manager) Delete(name string) error {
 		return fmt.Errorf("failed to delete key: %w", err)
 	}
 
+	if m.Current() == name {
+		if err := os.Remove(filepath.Join(m.keysDir, currentFile)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset current key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Current returns the name of the key used when no host binding matches
+func (m *Manager) Current() string {
+	data, err := os.ReadFile(filepath.Join(m.keysDir, currentFile))
+	if err != nil {
+		return DefaultKeyName
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return DefaultKeyName
+	}
+
+	return name
+}
+
+// Use makes the named key the current key
+func (m *Manager) Use(name string) error {
+	if _, err := m.Load(name); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(m.keysDir, currentFile), []byte(name), 0600); err != nil {
+		return fmt.Errorf("failed to set current key: %w", err)
+	}
+
 	return nil
 }
 
+// Bindings returns the host pattern to key name mappings in match order
+func (m *Manager) Bindings() ([]Binding, error) {
+	data, err := os.ReadFile(filepath.Join(m.keysDir, bindingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Binding{}, nil
+		}
+		return nil, fmt.Errorf("failed to read key bindings: %w", err)
+	}
+
+	var bindings []Binding
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to parse key bindings: %w", err)
+	}
+
+	return bindings, nil
+}
+
+// Bind maps hosts matching pattern to the named key, replacing an existing binding for the pattern
+func (m *Manager) Bind(pattern, name string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+	}
+
+	if _, err := m.Load(name); err != nil {
+		return err
+	}
+
+	bindings, err := m.Bindings()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range bindings {
+		if bindings[i].Pattern == pattern {
+			bindings[i].Key = name
+			replaced = true
+		}
+	}
+	if !replaced {
+		bindings = append(bindings, Binding{Pattern: pattern, Key: name})
+	}
+
+	return m.saveBindings(bindings)
+}
+
+// Unbind removes the binding for a host pattern
+func (m *Manager) Unbind(pattern string) error {
+	bindings, err := m.Bindings()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]Binding, 0, len(bindings))
+	for _, binding := range bindings {
+		if binding.Pattern != pattern {
+			kept = append(kept, binding)
+		}
+	}
+
+	if len(kept) == len(bindings) {
+		return fmt.Errorf("no binding for %q", pattern)
+	}
+
+	return m.saveBindings(kept)
+}
+
+// KeyNameForHost returns the key bound to host. Patterns are matched against
+// the full address and against the host name without the port.
+func (m *Manager) KeyNameForHost(host string) (string, bool) {
+	bindings, err := m.Bindings()
+	if err != nil {
+		return "", false
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	for _, binding := range bindings {
+		if ok, _ := path.Match(binding.Pattern, host); ok {
+			return binding.Key, true
+		}
+		if ok, _ := path.Match(binding.Pattern, hostname); ok {
+			return binding.Key, true
+		}
+	}
+
+	return "", false
+}
+
 // GetDefaultKeyPath returns the path to the default key file
 func (m *Manager) GetDefaultKeyPath() string {
 	return filepath.Join(m.keysDir, DefaultKeyName)
 }
+
+func (m *Manager) saveBindings(bindings []Binding) error {
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key bindings: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(m.keysDir, bindingsFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to save key bindings: %w", err)
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid key name %q", name)
+	}
+	return nil
+}
@@ -1,12 +1,18 @@
 package keystore
 
 import (
+	"context"
 	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
 )
 
 // KeyInfo stores metadata about an API key
@@ -14,6 +20,16 @@ type KeyInfo struct {
 	Key         string    `json:"key"`
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
+	Scopes      []string  `json:"scopes,omitempty"`
+
+	// 로테이션으로 교체된 키는 유예 기간이 끝날 때까지 유효
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+}
+
+// IsExpired returns true if the key's rotation grace period has ended
+func (k *KeyInfo) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
 }
 
 // Store manages API keys on the server side
@@ -21,6 +37,10 @@ type Store struct {
 	mu       sync.RWMutex
 	keys     map[string]*KeyInfo
 	filePath string
+
+	// 마지막으로 읽거나 쓴 파일 상태 (변경 감지용)
+	fileModTime time.Time
+	fileSize    int64
 }
 
 // NewStore creates a new key store
@@ -39,36 +59,162 @@ func NewStore(filePath string) (*Store, error) {
 	return store, nil
 }
 
-// Add adds a new API key to the store
-func (s *Store) Add(key, description string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Add adds a new API key to the store.
+// Scopes such as "runtime:java" or "rpc:ObserveRuntimes" restrict what the key may access.
+func (s *Store) Add(key, description string, scopes []string) error {
+	return s.update(func() error {
+		if _, exists := s.keys[key]; exists {
+			return fmt.Errorf("key already exists")
+		}
 
-	if _, exists := s.keys[key]; exists {
-		return fmt.Errorf("key already exists")
-	}
+		s.keys[key] = &KeyInfo{
+			Key:         key,
+			Description: description,
+			CreatedAt:   time.Now(),
+			Scopes:      scopes,
+		}
 
-	s.keys[key] = &KeyInfo{
-		Key:         key,
-		Description: description,
-		CreatedAt:   time.Now(),
+		return nil
+	})
+}
+
+// Import adds keys exported from another keystore and returns how many were added.
+// Existing keys are skipped unless overwrite is true. Nothing is imported if an entry
+// has no key or repeats an earlier one.
+func (s *Store) Import(keys []*KeyInfo, overwrite bool) (int, error) {
+	added := 0
+
+	err := s.update(func() error {
+		seen := make(map[string]bool, len(keys))
+		for i, info := range keys {
+			if info == nil || info.Key == "" {
+				return fmt.Errorf("invalid key entry %d: missing key", i)
+			}
+			if seen[info.Key] {
+				return fmt.Errorf("invalid key entry %d: duplicate key", i)
+			}
+			seen[info.Key] = true
+		}
+
+		for _, info := range keys {
+			if _, exists := s.keys[info.Key]; exists && !overwrite {
+				continue
+			}
+
+			imported := *info
+			if imported.CreatedAt.IsZero() {
+				imported.CreatedAt = time.Now()
+			}
+			s.keys[info.Key] = &imported
+			added++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return s.save()
+	return added, nil
 }
 
 // Remove removes an API key from the store
 func (s *Store) Remove(key string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.update(func() error {
+		if _, exists := s.keys[key]; !exists {
+			return fmt.Errorf("key not found")
+		}
+
+		delete(s.keys, key)
+
+		return nil
+	})
+}
+
+// ErrAlreadyRotated is returned when rotating a key that already has a successor. Handing
+// out the existing successor would let anyone holding a leaked old key fetch the new one.
+var ErrAlreadyRotated = errors.New("key was already rotated")
+
+// Rotate replaces oldKey with newKey. The successor inherits the description and scopes,
+// and oldKey stays valid for the grace period so clients can switch over without downtime.
+// It returns the replaced key (with its ExpiresAt, or nil ExpiresAt if it was revoked at
+// once) and the successor.
+func (s *Store) Rotate(oldKey, newKey string, grace time.Duration) (*KeyInfo, *KeyInfo, error) {
+	var replaced, successor *KeyInfo
+
+	err := s.update(func() error {
+		now := time.Now()
+		s.pruneExpired(now)
+
+		old, exists := s.keys[oldKey]
+		if !exists {
+			return fmt.Errorf("key not found")
+		}
+
+		if old.ReplacedBy != "" {
+			return ErrAlreadyRotated
+		}
+
+		if _, exists := s.keys[newKey]; exists {
+			return fmt.Errorf("key already exists")
+		}
+
+		successor = &KeyInfo{
+			Key:         newKey,
+			Description: old.Description,
+			CreatedAt:   now,
+			Scopes:      old.Scopes,
+		}
+		s.keys[newKey] = successor
+		replaced = old
+
+		if grace <= 0 {
+			delete(s.keys, oldKey)
+			return nil
+		}
+
+		expiresAt := now.Add(grace)
+		old.ExpiresAt = &expiresAt
+		old.ReplacedBy = newKey
 
-	if _, exists := s.keys[key]; !exists {
-		return fmt.Errorf("key not found")
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	delete(s.keys, key)
+	return replaced, successor, nil
+}
+
+// Find looks up a key by its full value, a unique prefix, or its masked form ("watcher_ab...wxyz")
+func (s *Store) Find(id string) (*KeyInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return s.save()
+	if info, exists := s.keys[id]; exists {
+		return info, nil
+	}
+
+	prefix, suffix := id, ""
+	if idx := strings.Index(id, "..."); idx >= 0 {
+		prefix, suffix = id[:idx], id[idx+3:]
+	}
+
+	var match *KeyInfo
+	for key, info := range s.keys {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix) {
+			if match != nil {
+				return nil, fmt.Errorf("key %q is ambiguous", id)
+			}
+			match = info
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("key not found")
+	}
+
+	return match, nil
 }
 
 // Validate checks if an API key is valid using constant-time comparison
@@ -81,13 +227,58 @@ func (s *Store) Validate(key string) bool {
 		return false // 변경: true → false
 	}
 
-	for storedKey := range s.keys {
-		if subtle.ConstantTimeCompare([]byte(key), []byte(storedKey)) == 1 {
-			return true
+	return s.lookup(key) != nil
+}
+
+// Scopes returns the scopes of a key, or nil if the key is unrestricted or unknown
+func (s *Store) Scopes(key string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info := s.lookup(key)
+	if info == nil {
+		return nil
+	}
+
+	return info.Scopes
+}
+
+// ResolveKeyID finds the non-expired key with the given auth.KeyID, for signed requests
+func (s *Store) ResolveKeyID(id string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for key, info := range s.keys {
+		if auth.KeyID(key) == id && !info.IsExpired(now) {
+			return key, true
 		}
 	}
 
-	return false
+	return "", false
+}
+
+// lookup finds a non-expired key using constant-time comparison. Callers must hold s.mu.
+func (s *Store) lookup(key string) *KeyInfo {
+	now := time.Now()
+
+	var found *KeyInfo
+	for storedKey, info := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(storedKey)) == 1 && !info.IsExpired(now) {
+			found = info
+		}
+	}
+
+	return found
+}
+
+// pruneExpired drops keys whose rotation grace period has ended. Callers must hold s.mu.
+func (s *Store) pruneExpired(now time.Time) {
+	for key, info := range s.keys {
+		if info.IsExpired(now) {
+			delete(s.keys, key)
+		}
+	}
 }
 
 // List returns all stored keys
@@ -105,20 +296,110 @@ func (s *Store) List() []*KeyInfo {
 
 // Clear removes all keys
 func (s *Store) Clear() error {
+	return s.update(func() error {
+		s.keys = make(map[string]*KeyInfo)
+		return nil
+	})
+}
+
+// IsEmpty returns true if no keys are stored
+func (s *Store) IsEmpty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.keys) == 0
+}
+
+// Reload re-reads the keys file, replacing the in-memory keys.
+// On a parse error the current keys are kept.
+func (s *Store) Reload() error {
+	if s.filePath == "" {
+		return nil
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.keys = make(map[string]*KeyInfo)
+	if err := s.load(); err != nil {
+		if os.IsNotExist(err) {
+			s.keys = make(map[string]*KeyInfo)
+			s.fileModTime = time.Time{}
+			s.fileSize = 0
+			return nil
+		}
+		return fmt.Errorf("failed to reload keys: %w", err)
+	}
 
-	return s.save()
+	return nil
 }
 
-// IsEmpty returns true if no keys are stored
-func (s *Store) IsEmpty() bool {
+// Watch polls the keys file and reloads the store whenever it changes on disk.
+// It blocks until ctx is cancelled. onReload, if non-nil, is called after every reload attempt.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, onReload func(error)) {
+	if s.filePath == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.changedOnDisk() {
+				continue
+			}
+
+			err := s.Reload()
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}
+}
+
+// changedOnDisk reports whether the keys file differs from the last state read or written
+func (s *Store) changedOnDisk() bool {
+	info, err := os.Stat(s.filePath)
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return len(s.keys) == 0
+	if err != nil {
+		// 파일이 삭제된 경우에도 변경으로 간주
+		return os.IsNotExist(err) && !s.fileModTime.IsZero()
+	}
+
+	return !info.ModTime().Equal(s.fileModTime) || info.Size() != s.fileSize
+}
+
+// update runs fn against the latest on-disk keys while holding the file lock and
+// then persists the result, so concurrent wsctl invocations don't clobber each other.
+func (s *Store) update(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.filePath == "" {
+		return fn()
+	}
+
+	unlock, err := lockFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.load(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to load keys: %w", err)
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	return s.save()
 }
 
 func (s *Store) load() error {
@@ -129,16 +410,27 @@ func (s *Store) load() error {
 
 	var keys []*KeyInfo
 	if err := json.Unmarshal(data, &keys); err != nil {
+		// 같은 파일을 다시 읽지 않도록 상태는 기록 (Watch가 매번 같은 오류를 내지 않게)
+		s.recordFileState()
 		return fmt.Errorf("failed to parse keys file: %w", err)
 	}
 
-	for _, info := range keys {
-		s.keys[info.Key] = info
+	loaded := make(map[string]*KeyInfo, len(keys))
+	for i, info := range keys {
+		if info == nil || info.Key == "" {
+			s.recordFileState()
+			return fmt.Errorf("failed to parse keys file: entry %d has no key", i)
+		}
+		loaded[info.Key] = info
 	}
+	s.keys = loaded
+
+	s.recordFileState()
 
 	return nil
 }
 
+// save writes the keys atomically: a temp file in the same directory is renamed over the target
 func (s *Store) save() error {
 	if s.filePath == "" {
 		return nil
@@ -154,9 +446,48 @@ func (s *Store) save() error {
 		return fmt.Errorf("failed to encode keys: %w", err)
 	}
 
-	if err := os.WriteFile(s.filePath, data, 0600); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write keys file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keys file: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keys file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keys file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("failed to write keys file: %w", err)
 	}
 
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return fmt.Errorf("failed to write keys file: %w", err)
+	}
+
+	s.recordFileState()
+
 	return nil
 }
+
+// recordFileState remembers the keys file's mtime and size. Callers must hold s.mu.
+func (s *Store) recordFileState() {
+	info, err := os.Stat(s.filePath)
+	if err != nil {
+		return
+	}
+
+	s.fileModTime = info.ModTime()
+	s.fileSize = info.Size()
+}
@@ -0,0 +1,261 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestStore returns a store backed by a keys file in a temporary directory
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestReload(t *testing.T) {
+	s, path := newTestStore(t)
+	if err := s.Add("watcher_old", "old", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다른 프로세스(wsctl key ...)가 파일을 바꾼 것처럼 두 번째 store로 수정
+	other, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Add("watcher_new", "new", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Remove("watcher_old"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !s.Validate("watcher_new") {
+		t.Error("added key is not valid after Reload()")
+	}
+	if s.Validate("watcher_old") {
+		t.Error("revoked key is still valid after Reload()")
+	}
+
+	// 파일이 사라지면 모든 키가 무효가 됨
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload() of a missing file error = %v", err)
+	}
+	if !s.IsEmpty() {
+		t.Errorf("store still holds %d keys after the file was removed", len(s.List()))
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s, path := newTestStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := make(chan error, 10)
+	go s.Watch(ctx, 10*time.Millisecond, func(err error) { reloads <- err })
+
+	other, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		name   string
+		change func() error
+		valid  bool
+	}{
+		{name: "added", change: func() error { return other.Add("watcher_new", "new", nil) }, valid: true},
+		{name: "revoked", change: func() error { return other.Remove("watcher_new") }, valid: false},
+	} {
+		if err := step.change(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-reloads:
+			if err != nil {
+				t.Fatalf("reload after the key was %s: %v", step.name, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no reload after the key was %s", step.name)
+		}
+		if got := s.Validate("watcher_new"); got != step.valid {
+			t.Errorf("Validate() after the key was %s = %v, want %v", step.name, got, step.valid)
+		}
+	}
+}
+
+func TestReloadMalformedFileKeepsKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid JSON", data: "{not json"},
+		{name: "truncated", data: `[{"key": "watcher_new"`},
+		{name: "null entry", data: `[null]`},
+		{name: "entry without a key", data: `[{"description": "no key"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, path := newTestStore(t)
+			if err := s.Add("watcher_old", "old", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Reload(); err == nil {
+				t.Error("Reload() of a malformed file succeeded")
+			}
+			if !s.Validate("watcher_old") {
+				t.Error("previous key was dropped after a failed reload")
+			}
+
+			// 잘못된 파일을 덮어써서 키를 잃지 않도록 수정도 거부됨
+			if err := s.Add("watcher_new", "new", nil); err == nil {
+				t.Error("Add() over a malformed file succeeded")
+			}
+		})
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	_, path := newTestStore(t)
+
+	const writers, keysPerWriter = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*keysPerWriter)
+	for w := 0; w < writers; w++ {
+		// 각자 store를 가진 여러 wsctl 프로세스처럼 파일 잠금만 공유
+		s, err := NewStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < keysPerWriter; i++ {
+				errs <- s.Add(fmt.Sprintf("watcher_%d_%d", w, i), "", nil)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.List()); got != writers*keysPerWriter {
+		t.Errorf("keys file holds %d keys, want %d", got, writers*keysPerWriter)
+	}
+}
+
+func TestImport(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		keys      []*KeyInfo
+		overwrite bool
+		wantAdded int
+		wantErr   bool
+		wantDesc  string // description of watcher_old afterwards
+	}{
+		{name: "new keys", keys: []*KeyInfo{{Key: "watcher_a", CreatedAt: created}, {Key: "watcher_b"}}, wantAdded: 2, wantDesc: "old"},
+		{name: "existing key skipped", keys: []*KeyInfo{{Key: "watcher_old", Description: "imported"}}, wantDesc: "old"},
+		{name: "existing key replaced", keys: []*KeyInfo{{Key: "watcher_old", Description: "imported"}}, overwrite: true, wantAdded: 1, wantDesc: "imported"},
+		{name: "duplicate key", keys: []*KeyInfo{{Key: "watcher_a"}, {Key: "watcher_a", Description: "again"}}, wantErr: true, wantDesc: "old"},
+		{name: "null entry", keys: []*KeyInfo{{Key: "watcher_a"}, nil}, wantErr: true, wantDesc: "old"},
+		{name: "entry without a key", keys: []*KeyInfo{{Key: "watcher_a"}, {Description: "no key"}}, wantErr: true, wantDesc: "old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, path := newTestStore(t)
+			if err := s.Add("watcher_old", "old", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			added, err := s.Import(tt.keys, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Import() error = %v, want error %v", err, tt.wantErr)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("Import() added %d keys, want %d", added, tt.wantAdded)
+			}
+
+			// 저장된 파일을 다시 읽어 확인
+			reloaded, err := NewStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr && len(reloaded.List()) != 1 {
+				t.Errorf("failed Import() left %d keys, want only the existing one", len(reloaded.List()))
+			}
+			if info, err := reloaded.Find("watcher_old"); err != nil || info.Description != tt.wantDesc {
+				t.Errorf("watcher_old = %+v, %v, want description %q", info, err, tt.wantDesc)
+			}
+			for _, info := range reloaded.List() {
+				if info.CreatedAt.IsZero() {
+					t.Errorf("%s has no creation time", info.Key)
+				}
+				if info.Key == "watcher_a" && tt.keys[0].CreatedAt.Equal(created) && !info.CreatedAt.Equal(created) {
+					t.Errorf("%s created at %s, want the exported %s", info.Key, info.CreatedAt, created)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveFileMode(t *testing.T) {
+	s, path := newTestStore(t)
+
+	// 기존 파일 권한이 넓어도 저장하면 0600이 됨
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Import([]*KeyInfo{{Key: "watcher_a"}}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("keys file mode = %v, want 0600", mode)
+	}
+
+	// 임시 파일이 남지 않음
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "keys.json" && name != "keys.json.lock" {
+			t.Errorf("unexpected file %s left next to the keys file", name)
+		}
+	}
+}
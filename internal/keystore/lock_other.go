@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package keystore
+
+// lockFile is a no-op on platforms without flock (Windows, Solaris, AIX, ...); atomic renames still prevent torn writes
+func lockFile(path string) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,126 @@
+package labels
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Parse converts "key=value" pairs into a label set
+func Parse(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+
+	return labels, nil
+}
+
+// Format renders labels as a sorted "k1=v1,k2=v2" string
+func Format(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+type operator int
+
+const (
+	opEquals operator = iota
+	opNotEquals
+	opExists
+	opNotExists
+)
+
+type requirement struct {
+	key   string
+	op    operator
+	value string
+}
+
+// Selector filters label sets, e.g. "env=prod,role!=db,canary,!legacy"
+type Selector struct {
+	requirements []requirement
+}
+
+// ParseSelector parses a comma-separated list of requirements:
+// key=value (or key==value), key!=value, key (exists), !key (does not exist)
+func ParseSelector(selector string) (*Selector, error) {
+	s := &Selector{}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var req requirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = requirement{key: strings.TrimSpace(key), op: opNotEquals, value: strings.TrimSpace(value)}
+		case strings.Contains(term, "=="):
+			key, value, _ := strings.Cut(term, "==")
+			req = requirement{key: strings.TrimSpace(key), op: opEquals, value: strings.TrimSpace(value)}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			req = requirement{key: strings.TrimSpace(key), op: opEquals, value: strings.TrimSpace(value)}
+		case strings.HasPrefix(term, "!"):
+			req = requirement{key: strings.TrimSpace(term[1:]), op: opNotExists}
+		default:
+			req = requirement{key: term, op: opExists}
+		}
+
+		if req.key == "" {
+			return nil, fmt.Errorf("invalid selector term %q: missing key", term)
+		}
+		s.requirements = append(s.requirements, req)
+	}
+
+	return s, nil
+}
+
+// Empty returns true if the selector matches everything
+func (s *Selector) Empty() bool {
+	return s == nil || len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement of the selector
+func (s *Selector) Matches(labels map[string]string) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, req := range s.requirements {
+		value, exists := labels[req.key]
+		switch req.op {
+		case opEquals:
+			if !exists || value != req.value {
+				return false
+			}
+		case opNotEquals:
+			if exists && value == req.value {
+				return false
+			}
+		case opExists:
+			if !exists {
+				return false
+			}
+		case opNotExists:
+			if exists {
+				return false
+			}
+		}
+	}
+
+	return true
+}
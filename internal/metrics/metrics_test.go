@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(r *Registry)
+		want string
+	}{
+		{
+			name: "counter escaping",
+			fill: func(r *Registry) {
+				c := NewCounterVec(r, "test_total", "Help with \\ backslash\nand \"quotes\"", "path")
+				c.Inc(`C:\bin\java`)
+				c.Add(2, "say \"hi\"\nbye")
+			},
+			want: `# HELP test_total Help with \\ backslash\nand "quotes"
+# TYPE test_total counter
+test_total{path="C:\\bin\\java"} 1
+test_total{path="say \"hi\"\nbye"} 2
+`,
+		},
+		{
+			name: "gauge without labels and special values",
+			fill: func(r *Registry) {
+				NewGaugeVec(r, "test_plain", "No labels").Set(0.5)
+				g := NewGaugeVec(r, "test_special", "Special values", "kind")
+				g.Set(math.Inf(1), "pos")
+				g.Set(math.Inf(-1), "neg")
+				g.Set(math.NaN(), "nan")
+				g.Set(1e21, "large")
+			},
+			want: `# HELP test_plain No labels
+# TYPE test_plain gauge
+test_plain 0.5
+# HELP test_special Special values
+# TYPE test_special gauge
+test_special{kind="large"} 1e+21
+test_special{kind="nan"} NaN
+test_special{kind="neg"} -Inf
+test_special{kind="pos"} +Inf
+`,
+		},
+		{
+			name: "series sorted by label values and deleted by label",
+			fill: func(r *Registry) {
+				g := NewGaugeVec(r, "test_info", "Info", "runtime", "version")
+				g.Set(1, "node", "20")
+				g.Set(1, "java", "21")
+				g.Set(1, "java", "17")
+				g.DeleteMatching("version", "20")
+				g.DeleteMatching("unknown", "17")
+			},
+			want: `# HELP test_info Info
+# TYPE test_info gauge
+test_info{runtime="java",version="17"} 1
+test_info{runtime="java",version="21"} 1
+`,
+		},
+		{
+			name: "empty family",
+			fill: func(r *Registry) {
+				NewCounterVec(r, "test_empty_total", "Nothing yet", "reason")
+			},
+			want: `# HELP test_empty_total Nothing yet
+# TYPE test_empty_total counter
+`,
+		},
+		{
+			name: "histogram",
+			fill: func(r *Registry) {
+				h := NewHistogramVec(r, "test_seconds", "Durations", []float64{0.1, 1, 2.5}, "detector")
+				h.Observe(0.25, "java")
+				h.Observe(0.5, "java")
+				h.Observe(4, "java")
+				h.Observe(0.1, "go")
+			},
+			want: `# HELP test_seconds Durations
+# TYPE test_seconds histogram
+test_seconds_bucket{detector="go",le="0.1"} 1
+test_seconds_bucket{detector="go",le="1"} 1
+test_seconds_bucket{detector="go",le="2.5"} 1
+test_seconds_bucket{detector="go",le="+Inf"} 1
+test_seconds_sum{detector="go"} 0.1
+test_seconds_count{detector="go"} 1
+test_seconds_bucket{detector="java",le="0.1"} 0
+test_seconds_bucket{detector="java",le="1"} 2
+test_seconds_bucket{detector="java",le="2.5"} 2
+test_seconds_bucket{detector="java",le="+Inf"} 3
+test_seconds_sum{detector="java"} 4.75
+test_seconds_count{detector="java"} 3
+`,
+		},
+		{
+			name: "histogram without labels",
+			fill: func(r *Registry) {
+				NewHistogramVec(r, "test_plain_seconds", "Plain", []float64{1}).Observe(2)
+			},
+			want: `# HELP test_plain_seconds Plain
+# TYPE test_plain_seconds histogram
+test_plain_seconds_bucket{le="1"} 0
+test_plain_seconds_bucket{le="+Inf"} 1
+test_plain_seconds_sum 2
+test_plain_seconds_count 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.fill(r)
+
+			var b strings.Builder
+			r.Write(&b)
+			if got := b.String(); got != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc() with a missing label value did not panic")
+		}
+	}()
+
+	NewCounterVec(NewRegistry(), "test_total", "Test", "a", "b").Inc("only-a")
+}
+
+func TestWriteTextfile(t *testing.T) {
+	r := NewRegistry()
+	NewGaugeVec(r, "test_up", "Up").Set(1)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.prom")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteTextfile(path, r); err != nil {
+		t.Fatalf("WriteTextfile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# HELP test_up Up\n# TYPE test_up gauge\ntest_up 1\n"; string(data) != want {
+		t.Errorf("textfile = %q, want %q", data, want)
+	}
+
+	// 임시 파일이 남지 않아야 함
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d files, want only the textfile", len(entries))
+	}
+}
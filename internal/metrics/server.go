@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// watcher_runtime_info is served by wsctl run and written by wctl -o prom, so that
+// dashboards work with both; NewRuntimeInfo is its only definition
+const (
+	runtimeInfoName = "watcher_runtime_info"
+	runtimeInfoHelp = "Runtimes detected on a host; the value is always 1"
+)
+
+// NewRuntimeInfo registers watcher_runtime_info{host,runtime,version,path}
+func NewRuntimeInfo(r *Registry) *GaugeVec {
+	return NewGaugeVec(r, runtimeInfoName, runtimeInfoHelp, "host", "runtime", "version", "path")
+}
+
+// ServerMetrics are the metrics exported by wsctl run
+type ServerMetrics struct {
+	Registry *Registry
+
+	hostname          string
+	runtimeInfo       *GaugeVec
+	detectionDuration *HistogramVec
+	detectionErrors   *CounterVec
+	requests          *CounterVec
+	authFailures      *CounterVec
+	rateLimited       *CounterVec
+	lockouts          *CounterVec
+}
+
+// NewServerMetrics registers the server metrics in a new registry
+func NewServerMetrics() *ServerMetrics {
+	r := NewRegistry()
+	hostname, _ := os.Hostname()
+
+	return &ServerMetrics{
+		Registry:    r,
+		hostname:    hostname,
+		runtimeInfo: NewRuntimeInfo(r),
+		detectionDuration: NewHistogramVec(r, "watcher_detection_duration_seconds",
+			"Time spent running a detector", DefaultBuckets, "detector"),
+		detectionErrors: NewCounterVec(r, "watcher_detection_errors_total",
+			"Detector runs that failed", "detector"),
+		requests: NewCounterVec(r, "watcher_grpc_requests_total",
+			"gRPC requests handled, by method and status code", "method", "code"),
+		authFailures: NewCounterVec(r, "watcher_auth_failures_total",
+			"Requests rejected by authentication, by reason", "reason"),
+		rateLimited: NewCounterVec(r, "watcher_rate_limited_total",
+			"Requests rejected by rate limits, by limit", "limit"),
+		lockouts: NewCounterVec(r, "watcher_auth_lockouts_total",
+			"Clients locked out after repeated invalid API keys"),
+	}
+}
+
+// ObserveDetection records one detector run
+func (m *ServerMetrics) ObserveDetection(name string, runtime *detector.Runtime, duration time.Duration, err error) {
+	m.detectionDuration.Observe(duration.Seconds(), name)
+
+	if err != nil {
+		m.detectionErrors.Inc(name)
+		return
+	}
+	if runtime == nil {
+		return
+	}
+
+	// 버전이 바뀌면 이전 라벨 조합이 남지 않도록 런타임 단위로 교체
+	m.runtimeInfo.DeleteMatching("runtime", runtime.Name)
+	if runtime.Found {
+		m.runtimeInfo.Set(1, m.hostname, runtime.Name, runtime.Version, runtime.Path)
+	}
+}
+
+// AuthFailure counts a rejected request; use it with auth.WithFailureHook
+func (m *ServerMetrics) AuthFailure(ctx context.Context, fullMethod string, reason auth.FailureReason) {
+	m.authFailures.Inc(string(reason))
+}
+
+// Lockout counts a client locked out by auth.Guard; use it with Guard.OnLockout
+func (m *ServerMetrics) Lockout(client string, failures int, lockout time.Duration) {
+	m.lockouts.Inc()
+}
+
+// RateLimited counts a request rejected by a limit; use it with ratelimit.Limiter.OnReject
+func (m *ServerMetrics) RateLimited(ctx context.Context, fullMethod string, limit ratelimit.Limit) {
+	m.rateLimited.Inc(string(limit))
+}
+
+// UnaryServerInterceptor counts unary requests by method and status code
+func (m *ServerMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		m.requests.Inc(info.FullMethod, status.Code(err).String())
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts streaming requests by method and status code
+func (m *ServerMetrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		err := handler(srv, ss)
+		m.requests.Inc(info.FullMethod, status.Code(err).String())
+		return err
+	}
+}
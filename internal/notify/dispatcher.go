@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/labels"
+)
+
+// DefaultTimeout bounds how long one notification may take
+const DefaultTimeout = 10 * time.Second
+
+// DefaultRetries is how often a failed delivery is retried
+const DefaultRetries = 2
+
+const (
+	// 이벤트가 몰려도 고루틴 수가 늘지 않도록 고정된 worker가 큐를 처리함
+	queueSize = 256
+	workers   = 4
+
+	defaultRetryDelay = time.Second
+)
+
+// ErrQueueFull is reported when events arrive faster than they can be delivered
+var ErrQueueFull = errors.New("notification queue is full, event dropped")
+
+// rule is a configured notifier together with its triggers
+type rule struct {
+	name     string
+	notifier Notifier
+	timeout  time.Duration
+	retries  int
+	events   map[string]bool
+	runtimes map[string]bool
+	selector string
+	matcher  *labels.Selector
+}
+
+// Dispatcher sends events to every notifier whose triggers match
+type Dispatcher struct {
+	rules      []rule
+	onError    func(name string, err error)
+	retryDelay time.Duration
+
+	start sync.Once
+	queue chan delivery
+}
+
+// delivery is an event waiting to be sent to one notifier
+type delivery struct {
+	rule  rule
+	event Event
+}
+
+// NewDispatcher builds notifiers from config. onError, if non-nil, is called when
+// a notification can't be delivered.
+func NewDispatcher(cfgs []config.Notification, onError func(name string, err error)) (*Dispatcher, error) {
+	d := &Dispatcher{
+		onError:    onError,
+		retryDelay: defaultRetryDelay,
+		queue:      make(chan delivery, queueSize),
+	}
+
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("%s-%d", cfg.Type, i+1)
+		}
+
+		notifier, err := New(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		matcher, err := labels.ParseSelector(cfg.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("notification %q: invalid selector: %w", cfg.Name, err)
+		}
+
+		r := rule{
+			name:     cfg.Name,
+			notifier: notifier,
+			timeout:  cfg.Timeout,
+			retries:  DefaultRetries,
+			events:   toSet(cfg.Events),
+			runtimes: toSet(cfg.Runtimes),
+			selector: cfg.Selector,
+			matcher:  matcher,
+		}
+		if r.timeout <= 0 {
+			r.timeout = DefaultTimeout
+		}
+		if cfg.Retries != nil {
+			if *cfg.Retries < 0 {
+				return nil, fmt.Errorf("notification %q: retries must not be negative", cfg.Name)
+			}
+			r.retries = *cfg.Retries
+		}
+
+		for event := range r.events {
+			if event != RuntimeChanged && event != StatusChanged {
+				return nil, fmt.Errorf("notification %q: unknown event %q (expected %s or %s)", cfg.Name, event, RuntimeChanged, StatusChanged)
+			}
+		}
+
+		d.rules = append(d.rules, r)
+	}
+
+	return d, nil
+}
+
+// Names returns the names of the configured notifiers
+func (d *Dispatcher) Names() []string {
+	names := make([]string, 0, len(d.rules))
+	for _, r := range d.rules {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// StatusSelectors returns the host selectors whose comparison statuses must be tracked
+// for status_changed triggers ("" means all hosts)
+func (d *Dispatcher) StatusSelectors() []string {
+	seen := make(map[string]bool)
+	var selectors []string
+
+	for _, r := range d.rules {
+		if !r.wants(StatusChanged) || seen[r.selector] {
+			continue
+		}
+		seen[r.selector] = true
+		selectors = append(selectors, r.selector)
+	}
+
+	return selectors
+}
+
+// Dispatch queues events for delivery to every notifier whose triggers match. Events
+// are dropped, and reported as ErrQueueFull, while the queue is full.
+func (d *Dispatcher) Dispatch(events ...Event) {
+	d.start.Do(func() {
+		for i := 0; i < workers; i++ {
+			go d.work()
+		}
+	})
+
+	for _, event := range events {
+		for _, r := range d.rules {
+			if !r.matches(event) {
+				continue
+			}
+
+			select {
+			case d.queue <- delivery{rule: r, event: event}:
+			default:
+				d.failed(r.name, ErrQueueFull)
+			}
+		}
+	}
+}
+
+// work delivers queued events, retrying failures with exponential backoff
+func (d *Dispatcher) work() {
+	for job := range d.queue {
+		delay := d.retryDelay
+
+		err := job.rule.send(context.Background(), job.event)
+		for attempt := 0; err != nil && attempt < job.rule.retries && !isPermanent(err); attempt++ {
+			time.Sleep(delay)
+			delay *= 2
+			err = job.rule.send(context.Background(), job.event)
+		}
+
+		if err != nil {
+			d.failed(job.rule.name, err)
+		}
+	}
+}
+
+func (d *Dispatcher) failed(name string, err error) {
+	if d.onError != nil {
+		d.onError(name, err)
+	}
+}
+
+// Send delivers an event synchronously to the named notifier (all notifiers if name is
+// empty), ignoring triggers. It returns the result of each notifier by name.
+func (d *Dispatcher) Send(ctx context.Context, name string, event Event) (map[string]error, error) {
+	results := make(map[string]error)
+
+	for _, r := range d.rules {
+		if name != "" && r.name != name {
+			continue
+		}
+		results[r.name] = r.send(ctx, event)
+	}
+
+	if name != "" && len(results) == 0 {
+		return nil, fmt.Errorf("notification %q not found", name)
+	}
+
+	return results, nil
+}
+
+func (r rule) send(ctx context.Context, event Event) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.notifier.Notify(ctx, event)
+}
+
+func (r rule) wants(eventType string) bool {
+	return len(r.events) == 0 || r.events[eventType]
+}
+
+func (r rule) matches(event Event) bool {
+	if !r.wants(event.Type) {
+		return false
+	}
+
+	if len(r.runtimes) > 0 && !r.runtimes[event.Runtime] {
+		return false
+	}
+
+	// status_changed는 rule의 selector별로 계산되므로 selector가 같아야 함
+	if event.Type == StatusChanged {
+		return event.Selector == r.selector
+	}
+
+	return r.matcher.Matches(event.Labels)
+}
+
+// RuntimeEvents converts runtime changes of a host into runtime_changed events
+func RuntimeEvents(host string, hostLabels map[string]string, changes []history.Change) []Event {
+	events := make([]Event, 0, len(changes))
+
+	for _, change := range changes {
+		timestamp := time.Now()
+		if change.Timestamp > 0 {
+			timestamp = time.Unix(change.Timestamp, 0)
+		}
+
+		events = append(events, Event{
+			Type:            RuntimeChanged,
+			Timestamp:       timestamp,
+			Runtime:         change.Runtime,
+			Host:            host,
+			Labels:          hostLabels,
+			Change:          change.Kind(),
+			PreviousVersion: change.PreviousVersion,
+			Version:         change.Version,
+		})
+	}
+
+	return events
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
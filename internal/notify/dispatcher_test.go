@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/config"
+)
+
+func sampleChange() Event {
+	return Event{
+		Type:            RuntimeChanged,
+		Timestamp:       time.Unix(1700000000, 0).UTC(),
+		Runtime:         "java",
+		Host:            "web-12",
+		Labels:          map[string]string{"env": "prod"},
+		Change:          "changed",
+		PreviousVersion: "17.0.1",
+		Version:         "21.0.2",
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	type request struct {
+		contentType string
+		auth        string
+		body        map[string]interface{}
+	}
+	requests := make(chan request, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid JSON payload %q: %v", data, err)
+		}
+		requests <- request{contentType: r.Header.Get("Content-Type"), auth: r.Header.Get("Authorization"), body: body}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		cfg      config.Notification
+		wantAuth string
+		wantBody map[string]interface{}
+	}{
+		{
+			name:     "webhook",
+			cfg:      config.Notification{Name: "hook", Type: "webhook", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}},
+			wantAuth: "Bearer token",
+			wantBody: map[string]interface{}{
+				"type":             "runtime_changed",
+				"timestamp":        "2023-11-14T22:13:20Z",
+				"runtime":          "java",
+				"host":             "web-12",
+				"labels":           map[string]interface{}{"env": "prod"},
+				"change":           "changed",
+				"previous_version": "17.0.1",
+				"version":          "21.0.2",
+			},
+		},
+		{
+			name:     "slack",
+			cfg:      config.Notification{Name: "slack", Type: "slack", URL: server.URL},
+			wantBody: map[string]interface{}{"text": ":warning: watcher: java on web-12: 17.0.1 -> 21.0.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDispatcher([]config.Notification{tt.cfg}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := d.Send(context.Background(), "", sampleChange())
+			if err != nil || results[tt.cfg.Name] != nil {
+				t.Fatalf("Send() = %v, %v", results, err)
+			}
+
+			got := <-requests
+			if got.contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got.contentType)
+			}
+			if got.auth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", got.auth, tt.wantAuth)
+			}
+			if !reflect.DeepEqual(got.body, tt.wantBody) {
+				t.Errorf("payload = %v, want %v", got.body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDispatchRetries(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name         string
+		statuses     []int // status of each attempt; the last one repeats
+		retries      *int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{name: "delivered", statuses: []int{200}, wantAttempts: 1},
+		{name: "retried until delivered", statuses: []int{500, 503, 200}, wantAttempts: 3},
+		{name: "gives up after retries", statuses: []int{500}, wantAttempts: 3, wantErr: true},
+		{name: "no retries", statuses: []int{500}, retries: intPtr(0), wantAttempts: 1, wantErr: true},
+		{name: "client error is not retried", statuses: []int{400}, wantAttempts: 1, wantErr: true},
+		{name: "rate limited is retried", statuses: []int{429, 200}, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer server.Close()
+
+			done := make(chan error, 1)
+			d, err := NewDispatcher([]config.Notification{{Name: "hook", Type: "webhook", URL: server.URL, Retries: tt.retries}}, func(name string, err error) {
+				done <- err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.retryDelay = time.Millisecond
+
+			d.Dispatch(sampleChange())
+
+			// 성공하면 onError가 호출되지 않으므로 시도 횟수로 완료를 기다림
+			deadline := time.After(5 * time.Second)
+			for !tt.wantErr && attempts.Load() < tt.wantAttempts {
+				select {
+				case err := <-done:
+					t.Fatalf("delivery failed: %v", err)
+				case <-deadline:
+					t.Fatalf("got %d attempts, want %d", attempts.Load(), tt.wantAttempts)
+				case <-time.After(time.Millisecond):
+				}
+			}
+			if tt.wantErr {
+				select {
+				case <-done:
+				case <-deadline:
+					t.Fatal("failure was not reported")
+				}
+			}
+
+			// 추가 재시도가 없는지 확인
+			time.Sleep(20 * time.Millisecond)
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDispatchQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var dropped atomic.Int32
+	d, err := NewDispatcher([]config.Notification{{Name: "hook", Type: "webhook", URL: server.URL}}, func(name string, err error) {
+		if errors.Is(err, ErrQueueFull) {
+			dropped.Add(1)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make([]Event, queueSize+workers+10)
+	for i := range events {
+		events[i] = sampleChange()
+	}
+	d.Dispatch(events...)
+
+	if dropped.Load() == 0 {
+		t.Error("Dispatch() did not drop events while the queue was full")
+	}
+}
+
+func TestNewDispatcherErrors(t *testing.T) {
+	negative := -1
+
+	tests := []struct {
+		name string
+		cfg  config.Notification
+	}{
+		{name: "missing url", cfg: config.Notification{Type: "webhook"}},
+		{name: "unknown type", cfg: config.Notification{Type: "email"}},
+		{name: "unknown event", cfg: config.Notification{Type: "command", Command: "true", Events: []string{"deleted"}}},
+		{name: "negative retries", cfg: config.Notification{Type: "command", Command: "true", Retries: &negative}},
+		{name: "invalid selector", cfg: config.Notification{Type: "command", Command: "true", Selector: "=prod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDispatcher([]config.Notification{tt.cfg}, nil); err == nil {
+				t.Error("NewDispatcher() accepted an invalid notification")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/history"
+)
+
+// Event types
+const (
+	// RuntimeChanged is sent when a runtime is added, removed or changes version on a host
+	RuntimeChanged = "runtime_changed"
+	// StatusChanged is sent when the comparison status of a runtime across hosts flips, e.g. SAME to DIFF
+	StatusChanged = "status_changed"
+)
+
+// Event is the payload of a notification
+type Event struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Runtime   string    `json:"runtime"`
+
+	// runtime_changed
+	Host            string            `json:"host,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Change          string            `json:"change,omitempty"` // history.Added, Removed or Changed
+	PreviousVersion string            `json:"previous_version,omitempty"`
+	Version         string            `json:"version,omitempty"`
+
+	// status_changed
+	Selector       string            `json:"selector,omitempty"` // hosts compared; empty means all
+	PreviousStatus string            `json:"previous_status,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	Versions       map[string]string `json:"versions,omitempty"` // host → version
+}
+
+// Message returns a one-line human readable summary of the event
+func (e Event) Message() string {
+	if e.Type == StatusChanged {
+		hosts := "all hosts"
+		if e.Selector != "" {
+			hosts = "hosts matching " + e.Selector
+		}
+		return fmt.Sprintf("%s across %s: %s -> %s", e.Runtime, hosts, e.PreviousStatus, e.Status)
+	}
+
+	switch e.Change {
+	case history.Added:
+		return fmt.Sprintf("%s on %s: %s installed", e.Runtime, e.Host, e.Version)
+	case history.Removed:
+		return fmt.Sprintf("%s on %s: %s removed", e.Runtime, e.Host, e.PreviousVersion)
+	default:
+		return fmt.Sprintf("%s on %s: %s -> %s", e.Runtime, e.Host, e.PreviousVersion, e.Version)
+	}
+}
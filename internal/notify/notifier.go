@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/binaryarc/watcher/internal/config"
+)
+
+// Notifier delivers an event
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Webhook posts the event as JSON to a URL
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	return postJSON(ctx, w.client(), w.URL, w.Headers, event)
+}
+
+func (w *Webhook) client() *http.Client {
+	if w.Client != nil {
+		return w.Client
+	}
+	return http.DefaultClient
+}
+
+// Slack posts a Slack-compatible {"text": ...} message to an incoming webhook URL
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify implements Notifier
+func (s *Slack) Notify(ctx context.Context, event Event) error {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	payload := map[string]string{"text": ":warning: watcher: " + event.Message()}
+	return postJSON(ctx, client, s.URL, nil, payload)
+}
+
+// Command runs a local command through the shell. The event is passed as JSON on stdin
+// and as WATCHER_* environment variables.
+type Command struct {
+	Command string
+}
+
+// Notify implements Notifier
+func (c *Command) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"WATCHER_EVENT="+event.Type,
+		"WATCHER_MESSAGE="+event.Message(),
+		"WATCHER_RUNTIME="+event.Runtime,
+		"WATCHER_HOST="+event.Host,
+		"WATCHER_CHANGE="+event.Change,
+		"WATCHER_PREVIOUS_VERSION="+event.PreviousVersion,
+		"WATCHER_VERSION="+event.Version,
+		"WATCHER_SELECTOR="+event.Selector,
+		"WATCHER_PREVIOUS_STATUS="+event.PreviousStatus,
+		"WATCHER_STATUS="+event.Status,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command failed: %w: %s", err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// New creates the notifier described by a notification config
+func New(cfg config.Notification) (Notifier, error) {
+	switch cfg.Type {
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("notification %q: url is required", cfg.Name)
+		}
+		return &Webhook{URL: cfg.URL, Headers: cfg.Headers}, nil
+	case "slack":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("notification %q: url is required", cfg.Name)
+		}
+		return &Slack{URL: cfg.URL}, nil
+	case "command":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("notification %q: command is required", cfg.Name)
+		}
+		return &Command{Command: cfg.Command}, nil
+	default:
+		return nil, fmt.Errorf("notification %q: unknown type %q (expected webhook, slack or command)", cfg.Name, cfg.Type)
+	}
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(payload); err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("notification rejected: %s: %s", resp.Status, bytes.TrimSpace(detail))
+
+		// 4xx는 다시 보내도 같은 결과 (408, 429 제외)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return permanentError{err}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// permanentError is a failure that retrying won't fix
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error {
+	return e.error
+}
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+// HistoryEntry is one runtime change in a host's timeline
+type HistoryEntry struct {
+	Time            string `json:"time" yaml:"time"`
+	Runtime         string `json:"runtime" yaml:"runtime"`
+	Change          string `json:"change" yaml:"change"`
+	PreviousVersion string `json:"previous_version,omitempty" yaml:"previous_version,omitempty"`
+	Version         string `json:"version,omitempty" yaml:"version,omitempty"`
+	Path            string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// NewHistoryEntry converts a runtime change for printing
+func NewHistoryEntry(change history.Change) HistoryEntry {
+	return HistoryEntry{
+		Time:            time.Unix(change.Timestamp, 0).Format("2006-01-02 15:04:05"),
+		Runtime:         change.Runtime,
+		Change:          change.Kind(),
+		PreviousVersion: change.PreviousVersion,
+		Version:         change.Version,
+		Path:            change.Path,
+	}
+}
+
+// versionColumn shows "old -> new" for changed runtimes and the last version for removed ones
+func (e HistoryEntry) versionColumn() string {
+	switch e.Change {
+	case history.Changed:
+		return e.PreviousVersion + " -> " + e.Version
+	case history.Removed:
+		return e.PreviousVersion
+	default:
+		return e.Version
+	}
+}
+
+// PrintHistoryTable prints a timeline of runtime changes
+func PrintHistoryTable(entries []HistoryEntry) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Time", "Runtime", "Change", "Version", "Path"})
+
+	for _, entry := range entries {
+		table.Append([]string{entry.Time, entry.Runtime, entry.Change, entry.versionColumn(), entry.Path})
+	}
+
+	table.Render()
+}
+
+// PrintHistoryLine prints one runtime change as a line of text, for streaming output
+func PrintHistoryLine(entry HistoryEntry) {
+	line := fmt.Sprintf("%s  %-8s %-8s %s", entry.Time, entry.Runtime, entry.Change, entry.versionColumn())
+	if entry.Path != "" {
+		line += "  (" + entry.Path + ")"
+	}
+	fmt.Println(line)
+}
+
+// PrintHistoryLineJSON prints one runtime change as a single line of JSON
+func PrintHistoryLineJSON(entry HistoryEntry) error {
+	return json.NewEncoder(os.Stdout).Encode(entry)
+}
+
+// PrintHistoryLineYAML prints one runtime change as a YAML document
+func PrintHistoryLineYAML(entry HistoryEntry) error {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	fmt.Printf("---\n%s", data)
+	return nil
+}
+
+// PrintHistoryJSON prints a timeline of runtime changes in JSON format
+func PrintHistoryJSON(entries []HistoryEntry) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// PrintHistoryYAML prints a timeline of runtime changes in YAML format
+func PrintHistoryYAML(entries []HistoryEntry) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	defer encoder.Close()
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+// HostRuntimes holds the runtimes observed on one host
+type HostRuntimes struct {
+	Host     string              `json:"host" yaml:"host"`
+	Labels   map[string]string   `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Runtimes []*detector.Runtime `json:"runtimes" yaml:"runtimes"`
+	Error    string              `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// PrintHostRuntimesTable prints runtimes of several hosts in one table
+func PrintHostRuntimesTable(hosts []HostRuntimes) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Host", "Runtime", "Version", "Path"})
+
+	for _, host := range hosts {
+		if host.Error != "" {
+			table.Append([]string{host.Host, "ERROR", host.Error, ""})
+			continue
+		}
+
+		for _, rt := range host.Runtimes {
+			if rt.Found {
+				table.Append([]string{host.Host, rt.Name, rt.Version, rt.Path})
+			}
+		}
+	}
+
+	table.Render()
+}
+
+// PrintHostRuntimesJSON prints runtimes of several hosts in JSON format
+func PrintHostRuntimesJSON(hosts []HostRuntimes) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(hosts)
+}
+
+// PrintHostRuntimesYAML prints runtimes of several hosts in YAML format
+func PrintHostRuntimesYAML(hosts []HostRuntimes) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	defer encoder.Close()
+	return encoder.Encode(hosts)
+}
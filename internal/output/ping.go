@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+)
+
+// Auth results of a ping
+const (
+	AuthOK       = "ok"
+	AuthDisabled = "disabled"
+	AuthMissing  = "missing key"
+	AuthDenied   = "denied"
+	AuthUnknown  = "unknown"
+)
+
+// PingResult is the reachability, latency and auth status of one server
+type PingResult struct {
+	Host       string            `json:"host" yaml:"host"`
+	Reachable  bool              `json:"reachable" yaml:"reachable"`
+	Health     string            `json:"health,omitempty" yaml:"health,omitempty"`
+	LatencyMS  float64           `json:"latency_ms,omitempty" yaml:"latency_ms,omitempty"`
+	Auth       string            `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Version    string            `json:"version,omitempty" yaml:"version,omitempty"`
+	APIVersion string            `json:"api_version,omitempty" yaml:"api_version,omitempty"`
+	Uptime     string            `json:"uptime,omitempty" yaml:"uptime,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Error      string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// OK reports whether the server is reachable, serving and accepted the key
+func (r PingResult) OK() bool {
+	return r.Reachable && r.Health != "NOT_SERVING" && r.Auth != AuthMissing && r.Auth != AuthDenied
+}
+
+// PrintPingTable prints ping results in table format
+func PrintPingTable(results []PingResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Host", "Status", "Latency", "Auth", "Version", "Uptime", "Error"})
+
+	for _, r := range results {
+		latency := ""
+		if r.Reachable {
+			latency = fmt.Sprintf("%.1fms", r.LatencyMS)
+		}
+		table.Append([]string{r.Host, formatHealth(r), latency, r.Auth, r.Version, r.Uptime, r.Error})
+	}
+
+	table.Render()
+}
+
+func formatHealth(r PingResult) string {
+	switch {
+	case !r.Reachable:
+		return color("UNREACHABLE", "31") // red
+	case r.Health == "SERVING":
+		return color(r.Health, "32") // green
+	case r.Health == "NOT_SERVING":
+		return color(r.Health, "31")
+	default:
+		return color(r.Health, "33") // yellow
+	}
+}
+
+// PrintPingJSON prints ping results in JSON format
+func PrintPingJSON(results []PingResult) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// PrintPingYAML prints ping results in YAML format
+func PrintPingYAML(results []PingResult) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	defer encoder.Close()
+	return encoder.Encode(results)
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"os"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/metrics"
+)
+
+// TextfileName is the file written to a node_exporter textfile collector directory
+const TextfileName = "watcher_runtimes.prom"
+
+// RuntimesRegistry exposes runtimes of host as watcher_runtime_info, the same series
+// wsctl run serves on its metrics endpoint
+func RuntimesRegistry(host string, runtimes []*detector.Runtime) *metrics.Registry {
+	registry := metrics.NewRegistry()
+	info := metrics.NewRuntimeInfo(registry)
+
+	for _, rt := range runtimes {
+		if rt.Found {
+			info.Set(1, host, rt.Name, rt.Version, rt.Path)
+		}
+	}
+
+	setCollectedTimestamp(registry)
+	return registry
+}
+
+// HostRuntimesRegistry exposes runtimes of several hosts, with watcher_host_up
+// reporting which hosts could be observed
+func HostRuntimesRegistry(hosts []HostRuntimes) *metrics.Registry {
+	registry := metrics.NewRegistry()
+	info := metrics.NewRuntimeInfo(registry)
+	up := metrics.NewGaugeVec(registry, "watcher_host_up",
+		"Whether the host could be observed (1) or not (0)", "host")
+
+	for _, host := range hosts {
+		if host.Error != "" {
+			up.Set(0, host.Host)
+			continue
+		}
+
+		up.Set(1, host.Host)
+		for _, rt := range host.Runtimes {
+			if rt.Found {
+				info.Set(1, host.Host, rt.Name, rt.Version, rt.Path)
+			}
+		}
+	}
+
+	setCollectedTimestamp(registry)
+	return registry
+}
+
+// setCollectedTimestamp lets alerts catch a textfile that stopped being refreshed
+func setCollectedTimestamp(registry *metrics.Registry) {
+	collected := metrics.NewGaugeVec(registry, "watcher_runtimes_collected_timestamp_seconds",
+		"Unix time the runtimes were collected")
+	collected.Set(float64(time.Now().Unix()))
+}
+
+// PrintRuntimesProm prints runtimes of host in the Prometheus text format
+func PrintRuntimesProm(host string, runtimes []*detector.Runtime) {
+	RuntimesRegistry(host, runtimes).Write(os.Stdout)
+}
+
+// PrintHostRuntimesProm prints runtimes of several hosts in the Prometheus text format
+func PrintHostRuntimesProm(hosts []HostRuntimes) {
+	HostRuntimesRegistry(hosts).Write(os.Stdout)
+}
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/binaryarc/watcher/internal/metrics"
+)
+
+// runtimeInfoLines returns the watcher_runtime_info lines of a registry
+func runtimeInfoLines(r *metrics.Registry) []string {
+	var b strings.Builder
+	r.Write(&b)
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.Contains(line, "watcher_runtime_info") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestRuntimeInfoMatchesServer(t *testing.T) {
+	hostname, _ := os.Hostname()
+	java := &detector.Runtime{Name: "java", Version: "21.0.2", Path: "/usr/bin/java", Found: true}
+	missing := &detector.Runtime{Name: "node"}
+
+	server := metrics.NewServerMetrics()
+	server.ObserveDetection("java", java, time.Millisecond, nil)
+	server.ObserveDetection("node", missing, time.Millisecond, nil)
+	want := runtimeInfoLines(server.Registry)
+
+	tests := []struct {
+		name     string
+		registry *metrics.Registry
+	}{
+		{"single host", RuntimesRegistry(hostname, []*detector.Runtime{java, missing})},
+		{"several hosts", HostRuntimesRegistry([]HostRuntimes{
+			{Host: hostname, Runtimes: []*detector.Runtime{java, missing}},
+			{Host: "unreachable:9090", Error: "connection refused"},
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runtimeInfoLines(tt.registry)
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("watcher_runtime_info =\n%s\nwant the server's\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
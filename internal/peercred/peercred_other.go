@@ -0,0 +1,16 @@
+//go:build !linux
+
+package peercred
+
+import (
+	"errors"
+	"net"
+)
+
+// Supported reports whether peer credentials can be read on this platform
+const Supported = false
+
+// read is not implemented outside Linux; connections are treated as unidentified
+func read(conn *net.UnixConn) (uid, gid, pid int, err error) {
+	return 0, 0, 0, errors.New("peer credentials are not supported on this platform")
+}
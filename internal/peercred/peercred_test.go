@@ -0,0 +1,36 @@
+package peercred
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientID(t *testing.T) {
+	tests := []struct {
+		name string
+		peer *peer.Peer
+		want string
+	}{
+		{name: "no peer", want: ""},
+		{name: "local user", peer: &peer.Peer{Addr: &net.UnixAddr{Name: "@", Net: "unix"}, AuthInfo: AuthInfo{Known: true, UID: 1000}}, want: "uid:1000"},
+		{name: "IPv4", peer: &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 40000}}, want: "192.0.2.10"},
+		{name: "IPv4-mapped", peer: &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("::ffff:192.0.2.10"), Port: 40000}}, want: "192.0.2.10"},
+		{name: "IPv6 /64", peer: &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("2001:db8:1:2:ffff::9"), Port: 40000}}, want: "2001:db8:1:2::/64"},
+		{name: "unknown Unix peer", peer: &peer.Peer{Addr: &net.UnixAddr{Name: "/run/watcher.sock", Net: "unix"}}, want: "/run/watcher.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.peer != nil {
+				ctx = peer.NewContext(ctx, tt.peer)
+			}
+			if got := ClientID(ctx); got != tt.want {
+				t.Errorf("ClientID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
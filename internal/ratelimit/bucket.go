@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Rate allows Limit requests per second on average, with bursts of up to Burst requests
+type Rate struct {
+	Limit float64
+	Burst int
+}
+
+// Enabled reports whether the rate limits anything
+func (r Rate) Enabled() bool {
+	return r.Limit > 0
+}
+
+func (r Rate) burst() float64 {
+	if r.Burst < 1 {
+		return 1
+	}
+	return float64(r.Burst)
+}
+
+// bucket is a token bucket that starts full
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take removes a token if there is one, or returns how long until the next one is available
+func (b *bucket) take(rate Rate, now time.Time) (bool, time.Duration) {
+	b.refill(rate, now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / rate.Limit * float64(time.Second))
+	return false, wait
+}
+
+func (b *bucket) refill(rate Rate, now time.Time) {
+	b.tokens = min(rate.burst(), b.tokens+now.Sub(b.last).Seconds()*rate.Limit)
+	b.last = now
+}
+
+// bucketIdleTimeout is how long a bucket is kept after its last request. It is only
+// needed until the bucket is full again, so idle clients don't accumulate.
+const bucketIdleTimeout = 10 * time.Minute
+
+// buckets holds one token bucket per client (API key or peer address)
+type buckets struct {
+	rate Rate
+
+	mu        sync.Mutex
+	byClient  map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBuckets(rate Rate) *buckets {
+	return &buckets{rate: rate, byClient: make(map[string]*bucket)}
+}
+
+func (b *buckets) take(client string, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Sub(b.lastSweep) > bucketIdleTimeout {
+		b.sweep(now)
+	}
+
+	bk := b.byClient[client]
+	if bk == nil {
+		bk = &bucket{tokens: b.rate.burst(), last: now}
+		b.byClient[client] = bk
+	}
+	return bk.take(b.rate, now)
+}
+
+// sweep drops the buckets of clients that have been idle long enough to be full again
+func (b *buckets) sweep(now time.Time) {
+	for client, bk := range b.byClient {
+		if now.Sub(bk.last) > bucketIdleTimeout {
+			bk.refill(b.rate, now)
+			if bk.tokens >= b.rate.burst() {
+				delete(b.byClient, client)
+			}
+		}
+	}
+	b.lastSweep = now
+}
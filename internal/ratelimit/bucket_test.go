@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	type step struct {
+		after    time.Duration // since the previous step
+		wantOK   bool
+		wantWait time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		rate  Rate
+		steps []step
+	}{
+		{
+			name: "burst then wait",
+			rate: Rate{Limit: 2, Burst: 3},
+			steps: []step{
+				{wantOK: true},
+				{wantOK: true},
+				{wantOK: true},
+				{wantOK: false, wantWait: 500 * time.Millisecond},
+			},
+		},
+		{
+			name: "refills at the rate",
+			rate: Rate{Limit: 2, Burst: 1},
+			steps: []step{
+				{wantOK: true},
+				{after: 250 * time.Millisecond, wantOK: false, wantWait: 250 * time.Millisecond},
+				{after: 250 * time.Millisecond, wantOK: true},
+			},
+		},
+		{
+			name: "refill is capped at the burst",
+			rate: Rate{Limit: 10, Burst: 2},
+			steps: []step{
+				{after: time.Hour, wantOK: true},
+				{wantOK: true},
+				{wantOK: false, wantWait: 100 * time.Millisecond},
+			},
+		},
+		{
+			name: "zero burst allows one request",
+			rate: Rate{Limit: 1},
+			steps: []step{
+				{wantOK: true},
+				{wantOK: false, wantWait: time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBuckets(tt.rate)
+			now := time.Unix(1700000000, 0)
+
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				ok, wait := b.take("client", now)
+				if ok != s.wantOK || wait != s.wantWait {
+					t.Errorf("step %d: take() = %v, %s, want %v, %s", i, ok, wait, s.wantOK, s.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketsPerClient(t *testing.T) {
+	b := newBuckets(Rate{Limit: 1, Burst: 1})
+	now := time.Unix(1700000000, 0)
+
+	if ok, _ := b.take("a", now); !ok {
+		t.Fatal("first request of a was rejected")
+	}
+	if ok, _ := b.take("a", now); ok {
+		t.Error("second request of a was allowed")
+	}
+	if ok, _ := b.take("b", now); !ok {
+		t.Error("b was limited by a's bucket")
+	}
+}
+
+func TestBucketsSweep(t *testing.T) {
+	b := newBuckets(Rate{Limit: 1, Burst: 5})
+	now := time.Unix(1700000000, 0)
+
+	b.take("idle", now)
+	b.take("active", now.Add(bucketIdleTimeout))
+
+	// 마지막 정리 뒤 bucketIdleTimeout이 지나야 정리가 일어남
+	b.take("active", now.Add(bucketIdleTimeout+time.Second))
+
+	if _, ok := b.byClient["idle"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := b.byClient["active"]; !ok {
+		t.Error("active bucket was swept")
+	}
+}
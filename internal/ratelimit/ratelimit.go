@@ -0,0 +1,158 @@
+// Package ratelimit protects the server from clients that call it too often. Every
+// detection spawns version commands, so a single misbehaving client could otherwise
+// keep a production host busy.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/peercred"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Limit names the limit that rejected a request
+type Limit string
+
+// Limits passed to reject hooks
+const (
+	LimitKey  Limit = "key"
+	LimitPeer Limit = "peer"
+)
+
+// Config sets the limits; zero values disable them
+type Config struct {
+	// PerKey limits the requests made with each API key validated by the auth
+	// interceptor; without auth only PerPeer applies
+	PerKey Rate
+	// PerPeer limits the requests from each client address (or local user on a Unix socket)
+	PerPeer Rate
+}
+
+// Enabled reports whether any limit is set
+func (c Config) Enabled() bool {
+	return c.PerKey.Enabled() || c.PerPeer.Enabled()
+}
+
+// RejectHook is called when a request is rejected
+type RejectHook func(ctx context.Context, fullMethod string, limit Limit)
+
+// Limiter enforces a Config
+type Limiter struct {
+	perKey   *buckets
+	perPeer  *buckets
+	onReject []RejectHook
+}
+
+// New returns a Limiter for cfg
+func New(cfg Config) *Limiter {
+	l := &Limiter{}
+	if cfg.PerKey.Enabled() {
+		l.perKey = newBuckets(cfg.PerKey)
+	}
+	if cfg.PerPeer.Enabled() {
+		l.perPeer = newBuckets(cfg.PerPeer)
+	}
+	return l
+}
+
+// OnReject calls hook for every rejected request, e.g. to count them
+func (l *Limiter) OnReject(hook RejectHook) {
+	l.onReject = append(l.onReject, hook)
+}
+
+// UnaryServerInterceptor rejects calls over the limits with ResourceExhausted and a
+// RetryInfo detail telling the client when to try again. Place it after the auth
+// interceptor so that only valid API keys get a bucket.
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := l.allow(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies the per-key and per-peer limits to opening streams
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := l.allow(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (l *Limiter) allow(ctx context.Context, fullMethod string) error {
+	// 부하가 있어도 헬스 체크는 제한하지 않음
+	if strings.HasPrefix(fullMethod, auth.HealthServicePrefix) {
+		return nil
+	}
+
+	now := time.Now()
+
+	if l.perPeer != nil {
+		if ok, wait := l.perPeer.take(peercred.ClientID(ctx), now); !ok {
+			l.rejected(ctx, fullMethod, LimitPeer)
+			return exhausted("rate limit exceeded for this client", wait)
+		}
+	}
+
+	// 인증되지 않은 키까지 버킷을 만들면 임의의 키로 메모리를 채울 수 있음
+	if l.perKey != nil {
+		if key, ok := auth.ValidatedKey(ctx); ok {
+			if ok, wait := l.perKey.take(key, now); !ok {
+				l.rejected(ctx, fullMethod, LimitKey)
+				return exhausted("rate limit exceeded for this API key", wait)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *Limiter) rejected(ctx context.Context, fullMethod string, limit Limit) {
+	for _, hook := range l.onReject {
+		hook(ctx, fullMethod, limit)
+	}
+}
+
+// exhausted returns a ResourceExhausted error asking the client to retry after wait
+func exhausted(msg string, wait time.Duration) error {
+	wait = max(wait, time.Millisecond)
+	seconds := int64(math.Ceil(wait.Seconds()))
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s, retry in %ds", msg, seconds))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RetryDelay returns the delay suggested by a ResourceExhausted error, if any
+func RetryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// validKey accepts a single API key
+type validKey string
+
+func (k validKey) Validate(key string) bool { return key == string(k) }
+
+func TestPerKeyLimitsValidatedKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		auth        bool
+		key         string
+		wantLimited bool
+	}{
+		{name: "validated key", auth: true, key: "watcher_valid", wantLimited: true},
+		{name: "auth disabled", key: "watcher_anything"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Config{PerKey: Rate{Limit: 0.01, Burst: 1}})
+			info := &grpc.UnaryServerInfo{FullMethod: "/watcher.WatcherService/ObserveRuntimes"}
+			call := func(ctx context.Context) error {
+				_, err := l.UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req any) (any, error) { return nil, nil })
+				return err
+			}
+			if tt.auth {
+				limited := call
+				call = func(ctx context.Context) error {
+					_, err := auth.UnaryServerInterceptor(validKey("watcher_valid"))(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+						return nil, limited(ctx)
+					})
+					return err
+				}
+			}
+
+			var err error
+			for i := 0; i < 3 && err == nil; i++ {
+				err = call(metadata.NewIncomingContext(context.Background(), metadata.Pairs(auth.APIKeyHeader, tt.key)))
+			}
+			if limited := status.Code(err) == codes.ResourceExhausted; limited != tt.wantLimited {
+				t.Errorf("limited = %v (%v), want %v", limited, err, tt.wantLimited)
+			}
+
+			// 검증되지 않은 키는 버킷을 만들지 않음
+			l.perKey.mu.Lock()
+			buckets := len(l.perKey.byClient)
+			l.perKey.mu.Unlock()
+			if tt.wantLimited != (buckets == 1) {
+				t.Errorf("%d key buckets after calls limited = %v", buckets, tt.wantLimited)
+			}
+		})
+	}
+}
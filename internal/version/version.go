@@ -0,0 +1,25 @@
+// Package version describes the watcher build.
+//
+// Version and Commit are set at link time, e.g.:
+//
+//	go build -ldflags "-X github.com/binaryarc/watcher/internal/version.Version=v1.4.0"
+package version
+
+// APIVersion is the version of the watcher gRPC API (proto/watcher.proto).
+// It changes only when the API changes incompatibly.
+const APIVersion = "v1"
+
+var (
+	// Version is the release this binary was built from
+	Version = "dev"
+	// Commit is the git commit this binary was built from
+	Commit = ""
+)
+
+// String returns the version, with the commit if known
+func String() string {
+	if Commit == "" {
+		return Version
+	}
+	return Version + " (" + Commit + ")"
+}
@@ -0,0 +1,183 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/grpcclient"
+	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/spf13/cobra"
+)
+
+// Values of the persistent flags on the wctl root command
+var (
+	ConfigPath  string
+	ContextName string
+)
+
+var loadedConfig *config.ClientConfig
+
+// KeyManager returns the client key manager for ~/.watcher/keys
+func KeyManager() (*keymanager.Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	keysDir := filepath.Join(homeDir, ".watcher", "keys")
+	return keymanager.NewManager(keysDir)
+}
+
+// ClientConfig returns the wctl configuration (--config, $WATCHER_CONFIG or ~/.watcher/config)
+func ClientConfig() (*config.ClientConfig, error) {
+	if loadedConfig != nil {
+		return loadedConfig, nil
+	}
+
+	path := ConfigPath
+	if path == "" {
+		defaultPath, err := config.DefaultClientConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+
+	cfg, err := config.LoadClient(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedConfig = cfg
+	return cfg, nil
+}
+
+// CurrentContext returns the context selected with --context or current-context, if any
+func CurrentContext() (*config.Context, error) {
+	cfg, err := ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.Context(ContextName)
+}
+
+// APIKeyFor returns the API key to send to host, in order:
+// --api-key flag, WATCHER_API_KEY, the key of the configured server,
+// a key bound to the host pattern, the context key, then the current key.
+func APIKeyFor(cmd *cobra.Command, host string) string {
+	return apiKeyFor(cmd, host)
+}
+
+// apiKeyFor resolves the key for a server known under several names (address, inventory name)
+func apiKeyFor(cmd *cobra.Command, hosts ...string) string {
+	flags := cmd.Root().PersistentFlags()
+	if flags.Changed("api-key") {
+		apiKey, _ := flags.GetString("api-key")
+		return apiKey
+	}
+
+	if envKey := os.Getenv("WATCHER_API_KEY"); envKey != "" {
+		return envKey
+	}
+
+	manager, err := KeyManager()
+	if err != nil {
+		return ""
+	}
+
+	if cfg, err := ClientConfig(); err == nil {
+		for _, host := range hosts {
+			if _, server := cfg.LookupServer(host); server != nil && server.Key != "" {
+				return loadKey(manager, server.Key)
+			}
+		}
+	}
+
+	for _, host := range hosts {
+		if name, ok := manager.KeyNameForHost(address(host)); ok {
+			return loadKey(manager, name)
+		}
+	}
+
+	if ctx, err := CurrentContext(); err == nil && ctx != nil && ctx.Key != "" {
+		return loadKey(manager, ctx.Key)
+	}
+
+	return loadKey(manager, manager.Current())
+}
+
+// NewClient connects to host, which may be a configured server name or an address,
+// using the key and TLS settings that apply to it
+func NewClient(cmd *cobra.Command, host string) (*grpcclient.Client, error) {
+	return Dial(cmd, Target{Name: host, Address: host})
+}
+
+// Dial connects to a target, resolving its key by address first and then by name unless
+// the target has its own
+func Dial(cmd *cobra.Command, target Target) (*grpcclient.Client, error) {
+	host := target.Address
+	opts := grpcclient.Options{
+		APIKey:       target.APIKey,
+		SignRequests: SignRequests(cmd, host),
+	}
+	if opts.APIKey == "" {
+		opts.APIKey = apiKeyFor(cmd, target.Address, target.Name)
+	}
+	// --refresh는 runtimes 조회 명령에만 있음
+	opts.ForceRefresh, _ = cmd.Flags().GetBool("refresh")
+
+	cfg, err := ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, server := cfg.LookupServer(host); server != nil && server.TLS != nil {
+		tlsConfig, err := server.TLS.ClientTLS()
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings for %s: %w", host, err)
+		}
+		opts.TLS = tlsConfig
+	}
+
+	return grpcclient.NewClientWithOptions(address(host), opts)
+}
+
+// SignRequests reports whether requests to host are signed instead of carrying the key:
+// with --sign, or when the configured server has sign-requests set
+func SignRequests(cmd *cobra.Command, host string) bool {
+	if sign, _ := cmd.Root().PersistentFlags().GetBool("sign"); sign {
+		return true
+	}
+
+	cfg, err := ClientConfig()
+	if err != nil {
+		return false
+	}
+	_, server := cfg.LookupServer(host)
+	return server != nil && server.SignRequests
+}
+
+// address resolves a configured server name to its address
+func address(host string) string {
+	cfg, err := ClientConfig()
+	if err != nil {
+		return host
+	}
+
+	if _, server := cfg.LookupServer(host); server != nil {
+		return server.Address
+	}
+
+	return host
+}
+
+func loadKey(manager *keymanager.Manager, name string) string {
+	key, err := manager.Load(name)
+	if err != nil {
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,208 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/detector"
+	"github.com/spf13/cobra"
+)
+
+// ServerRuntimes holds the runtimes observed on one server
+type ServerRuntimes struct {
+	Host     string
+	Labels   map[string]string
+	Runtimes map[string]*detector.Runtime
+	Error    error
+}
+
+const hostRequestTimeout = 10 * time.Second
+
+// CollectorAddress returns the fleet collector given with --collector or set in the
+// current context, or "" if servers are queried directly
+func CollectorAddress(cmd *cobra.Command) string {
+	if addr, _ := cmd.Flags().GetString("collector"); addr != "" {
+		return addr
+	}
+
+	if ctx, err := CurrentContext(); err == nil && ctx != nil {
+		return ctx.Collector
+	}
+
+	return ""
+}
+
+// UsesCollector reports whether servers are queried through a collector
+func UsesCollector(cmd *cobra.Command) bool {
+	return CollectorAddress(cmd) != ""
+}
+
+// FetchAllServers queries all targets in parallel, or asks the collector given with
+// --collector or by the current context for their latest reports (every agent it knows if targets is empty).
+// Results keep the order of targets.
+//
+// With --selector, servers whose labels don't match are dropped; labels from the client
+// config take precedence over the ones a server advertises. Unreachable servers are
+// only kept if their configured labels matched.
+func FetchAllServers(cmd *cobra.Command, targets []Target) ([]ServerRuntimes, error) {
+	selector, err := Selector(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ServerRuntimes
+	if collectorAddr := CollectorAddress(cmd); collectorAddr != "" {
+		results, err = fetchFromCollector(cmd, collectorAddr, targets)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		results = fetchAllServers(cmd, targets)
+	}
+
+	if selector.Empty() {
+		return results, nil
+	}
+
+	selected := make([]ServerRuntimes, 0, len(results))
+	for _, result := range results {
+		if result.Error != nil && result.Labels == nil {
+			continue
+		}
+		if selector.Matches(result.Labels) {
+			selected = append(selected, result)
+		}
+	}
+
+	return selected, nil
+}
+
+func fetchAllServers(cmd *cobra.Command, targets []Target) []ServerRuntimes {
+	var wg sync.WaitGroup
+	results := make([]ServerRuntimes, len(targets))
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(index int, target Target) {
+			defer wg.Done()
+
+			client, err := Dial(cmd, target)
+			if err != nil {
+				results[index] = ServerRuntimes{
+					Host:   target.Name,
+					Labels: target.Labels,
+					Error:  err,
+				}
+				return
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), hostRequestTimeout)
+			defer cancel()
+
+			observation, err := client.Observe(ctx)
+			if err != nil {
+				results[index] = ServerRuntimes{
+					Host:   target.Name,
+					Labels: target.Labels,
+					Error:  err,
+				}
+				return
+			}
+
+			results[index] = ServerRuntimes{
+				Host:     target.Name,
+				Labels:   mergeLabels(observation.Labels, target.Labels),
+				Runtimes: runtimeMap(observation.Runtimes),
+				Error:    nil,
+			}
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// fetchFromCollector asks a collector for the latest reports of targets in one round trip
+func fetchFromCollector(cmd *cobra.Command, collectorAddr string, targets []Target) ([]ServerRuntimes, error) {
+	client, err := NewClient(cmd, collectorAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	hosts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		hosts = append(hosts, address(target.Address))
+	}
+
+	// 호스트를 지정하면 응답 순서를 targets와 맞추기 위해 selector는 클라이언트에서 적용
+	var selector string
+	if len(targets) == 0 {
+		selector, _ = cmd.Flags().GetString("selector")
+	}
+
+	fleet, err := client.QueryFleet(context.Background(), hosts, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collector %s: %w", collectorAddr, err)
+	}
+	if len(targets) > 0 && len(fleet) != len(targets) {
+		return nil, fmt.Errorf("collector %s returned %d report(s) for %d host(s)", collectorAddr, len(fleet), len(targets))
+	}
+
+	results := make([]ServerRuntimes, 0, len(fleet))
+	for i, host := range fleet {
+		name, configured := host.Host, map[string]string(nil)
+		if len(targets) > 0 {
+			name, configured = targets[i].Name, targets[i].Labels
+		}
+
+		if host.Observation == nil {
+			reason := host.Error
+			if reason == "" {
+				reason = "not observed yet"
+			}
+			results = append(results, ServerRuntimes{
+				Host:   name,
+				Labels: configured,
+				Error:  errors.New(reason),
+			})
+			continue
+		}
+
+		results = append(results, ServerRuntimes{
+			Host:     name,
+			Labels:   mergeLabels(host.Observation.Labels, configured),
+			Runtimes: runtimeMap(host.Observation.Runtimes),
+		})
+	}
+
+	return results, nil
+}
+
+func runtimeMap(runtimes []*detector.Runtime) map[string]*detector.Runtime {
+	m := make(map[string]*detector.Runtime, len(runtimes))
+	for _, rt := range runtimes {
+		m[rt.Name] = rt
+	}
+	return m
+}
+
+// mergeLabels combines advertised labels with configured ones; configured labels win
+func mergeLabels(advertised, configured map[string]string) map[string]string {
+	if len(advertised) == 0 && len(configured) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(advertised)+len(configured))
+	for k, v := range advertised {
+		merged[k] = v
+	}
+	for k, v := range configured {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,171 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/inventory"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/spf13/cobra"
+)
+
+// Target is a server selected by a multi-host command
+type Target struct {
+	Name    string // label shown in output
+	Address string // configured server name or host:port
+	// Labels from the client config; nil if the server is not configured with labels
+	Labels map[string]string
+	// APIKey overrides the key resolved for the target
+	APIKey string
+}
+
+// AddTargetFlags registers the flags used to select servers for multi-host commands
+func AddTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("hosts", []string{}, "Comma-separated list of server addresses or configured server names")
+	cmd.Flags().String("group", "", "Servers of a configured group")
+	cmd.Flags().String("inventory", "", "Inventory file (Ansible INI/YAML, SSH config, or plain host list)")
+	cmd.Flags().String("limit", "", "Limit inventory hosts by group or host pattern (e.g., web:&prod:!web-01)")
+	cmd.Flags().String("collector", "", "Query the latest reports of a fleet collector instead of each server")
+	cmd.Flags().StringP("selector", "l", "", "Only include servers whose labels match (e.g., env=prod,role!=db)")
+	cmd.MarkFlagsMutuallyExclusive("hosts", "group", "inventory")
+}
+
+// HasTargetFlags reports whether servers were selected explicitly on the command line
+func HasTargetFlags(cmd *cobra.Command) bool {
+	for _, name := range []string{"hosts", "group", "inventory", "selector", "collector"} {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveTargets returns the servers of a multi-host command from --hosts, --group,
+// --inventory (narrowed by --limit), or else the current context.
+// Servers whose configured labels don't match --selector are left out.
+func ResolveTargets(cmd *cobra.Command) ([]Target, error) {
+	selector, err := Selector(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]Target, 0, len(targets))
+	for _, target := range targets {
+		target.Labels = configuredLabels(cfg, target.Address)
+		if target.Labels != nil && !selector.Matches(target.Labels) {
+			continue
+		}
+		selected = append(selected, target)
+	}
+
+	return selected, nil
+}
+
+// Selector returns the label selector given with --selector (empty if not set)
+func Selector(cmd *cobra.Command) (*labels.Selector, error) {
+	raw, _ := cmd.Flags().GetString("selector")
+
+	selector, err := labels.ParseSelector(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	return selector, nil
+}
+
+// configuredLabels returns the labels of a server defined in the client config
+func configuredLabels(cfg *config.ClientConfig, host string) map[string]string {
+	_, server := cfg.LookupServer(host)
+	if server == nil || len(server.Labels) == 0 {
+		return nil
+	}
+	return server.Labels
+}
+
+func resolveTargets(cmd *cobra.Command) ([]Target, error) {
+	flags := cmd.Flags()
+
+	inventoryPath, _ := flags.GetString("inventory")
+	limit, _ := flags.GetString("limit")
+	if limit != "" && inventoryPath == "" {
+		return nil, fmt.Errorf("--limit requires --inventory")
+	}
+
+	hosts, _ := flags.GetStringSlice("hosts")
+	var targets []Target
+	for _, host := range hosts {
+		if host = strings.TrimSpace(host); host != "" {
+			targets = append(targets, Target{Name: host, Address: host})
+		}
+	}
+	if len(targets) > 0 {
+		return targets, nil
+	}
+
+	cfg, err := ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	group, _ := flags.GetString("group")
+	if group != "" {
+		members, err := cfg.Group(group)
+		if err != nil {
+			return nil, err
+		}
+		return namedTargets(members), nil
+	}
+
+	if inventoryPath != "" {
+		inv, err := inventory.Load(inventoryPath)
+		if err != nil {
+			return nil, err
+		}
+
+		selected, err := inv.Select(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, host := range selected {
+			targets = append(targets, Target{Name: host.Name, Address: host.Target()})
+		}
+		return targets, nil
+	}
+
+	ctx, err := cfg.Context(ContextName)
+	if err != nil {
+		return nil, err
+	}
+	if ctx != nil {
+		if ctx.Group != "" {
+			members, err := cfg.Group(ctx.Group)
+			if err != nil {
+				return nil, err
+			}
+			return namedTargets(members), nil
+		}
+		return namedTargets(ctx.Servers), nil
+	}
+
+	return nil, nil
+}
+
+func namedTargets(hosts []string) []Target {
+	targets := make([]Target, 0, len(hosts))
+	for _, host := range hosts {
+		targets = append(targets, Target{Name: host, Address: host})
+	}
+	return targets
+}
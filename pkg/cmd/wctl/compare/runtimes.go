@@ -1,15 +1,13 @@
 package compare
 
 import (
-	"context"
 	"fmt"
+	"net"
 	"strings"
-	"sync"
-	"time"
 
-	"github.com/binaryarc/watcher/internal/detector"
-	"github.com/binaryarc/watcher/internal/grpcclient"
+	"github.com/binaryarc/watcher/internal/comparison"
 	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/spf13/cobra"
 )
 
@@ -19,45 +17,57 @@ var runtimesCmd = &cobra.Command{
 	Long: `Compare runtime versions across multiple servers to identify version inconsistencies.
 
 This command queries multiple servers in parallel and displays a comparison table
-showing which runtimes have different versions across your infrastructure.`,
+showing which runtimes have different versions across your infrastructure.
+
+Use -l to only compare servers with matching labels, e.g. -l env=prod,role!=db.
+
+With --collector the latest reports are read from a fleet collector (wsctl collector)
+in a single request instead of contacting every server. --refresh makes servers re-run
+detection instead of answering from their cache (wsctl run --cache-ttl).`,
 	Run: runCompareRuntimes,
 }
 
 func init() {
-	runtimesCmd.Flags().StringSlice("hosts", []string{}, "Comma-separated list of server addresses (required)")
-	runtimesCmd.MarkFlagRequired("hosts")
+	runtimesCmd.Flags().Bool("refresh", false, "Ask servers to re-run detection instead of answering from their cache")
+	common.AddTargetFlags(runtimesCmd)
 }
 
-type ServerRuntimes struct {
-	Host     string
-	Runtimes map[string]*detector.Runtime
-	Error    error
-}
-
-const hostRequestTimeout = 10 * time.Second
-
 func runCompareRuntimes(cmd *cobra.Command, args []string) {
-	hosts, _ := cmd.Flags().GetStringSlice("hosts")
-	for i, host := range hosts {
-		hosts[i] = strings.TrimSpace(host)
-	}
 	outputFmt, _ := cmd.Flags().GetString("output")
 
-	if len(hosts) == 0 {
-		fmt.Println("Error: --hosts flag is required")
+	targets, err := common.ResolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if len(targets) == 0 && !common.UsesCollector(cmd) {
+		fmt.Println("Error: no servers to compare")
+		fmt.Println("Use --hosts, --group, --inventory, --collector, or a context with a group (wctl config set-context)")
 		fmt.Println("Example: wctl compare runtimes --hosts server1:9090,server2:9090")
 		return
 	}
 
 	if outputFmt == "table" {
-		fmt.Printf("Comparing runtimes across %d server(s)...\n\n", len(hosts))
+		if len(targets) == 0 {
+			fmt.Println("Comparing runtimes across all collector agents...")
+			fmt.Println()
+		} else {
+			fmt.Printf("Comparing runtimes across %d server(s)...\n\n", len(targets))
+		}
 	}
 
-	apiKey, _ := cmd.Root().PersistentFlags().GetString("api-key")
-
-	serverResults := fetchAllServers(hosts, apiKey, outputFmt)
+	serverResults, err := common.FetchAllServers(cmd, targets)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(serverResults) == 0 {
+		fmt.Println("No matching servers found")
+		return
+	}
 
-	var successfulServers []ServerRuntimes
+	var successfulServers []common.ServerRuntimes
 	for _, result := range serverResults {
 		if result.Error != nil {
 			if outputFmt == "table" {
@@ -73,7 +83,7 @@ func runCompareRuntimes(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	if outputFmt == "table" && len(successfulServers) < len(hosts) {
+	if outputFmt == "table" && len(successfulServers) < len(serverResults) {
 		fmt.Println()
 	}
 
@@ -95,55 +105,7 @@ func runCompareRuntimes(cmd *cobra.Command, args []string) {
 	}
 }
 
-func fetchAllServers(hosts []string, apiKey string, outputFmt string) []ServerRuntimes {
-	var wg sync.WaitGroup
-	results := make([]ServerRuntimes, len(hosts))
-
-	for i, host := range hosts {
-		wg.Add(1)
-		go func(index int, hostAddr string) {
-			defer wg.Done()
-
-			client, err := grpcclient.NewClient(hostAddr, apiKey)
-			if err != nil {
-				results[index] = ServerRuntimes{
-					Host:  hostAddr,
-					Error: err,
-				}
-				return
-			}
-			defer client.Close()
-
-			ctx, cancel := context.WithTimeout(context.Background(), hostRequestTimeout)
-			defer cancel()
-
-			runtimes, err := client.ObserveRuntimes(ctx)
-			if err != nil {
-				results[index] = ServerRuntimes{
-					Host:  hostAddr,
-					Error: err,
-				}
-				return
-			}
-
-			runtimeMap := make(map[string]*detector.Runtime)
-			for _, rt := range runtimes {
-				runtimeMap[rt.Name] = rt
-			}
-
-			results[index] = ServerRuntimes{
-				Host:     hostAddr,
-				Runtimes: runtimeMap,
-				Error:    nil,
-			}
-		}(i, host)
-	}
-
-	wg.Wait()
-	return results
-}
-
-func buildComparison(serverResults []ServerRuntimes) *output.ComparisonData {
+func buildComparison(serverResults []common.ServerRuntimes) *output.ComparisonData {
 	runtimeNames := make(map[string]bool)
 	for _, server := range serverResults {
 		if server.Error == nil {
@@ -158,15 +120,15 @@ func buildComparison(serverResults []ServerRuntimes) *output.ComparisonData {
 		versions := make([]string, len(serverResults))
 		for i, server := range serverResults {
 			if server.Error != nil {
-				versions[i] = "ERROR"
+				versions[i] = comparison.VersionError
 			} else if rt, found := server.Runtimes[name]; found {
 				versions[i] = rt.Version
 			} else {
-				versions[i] = "x"
+				versions[i] = comparison.VersionMissing
 			}
 		}
 
-		status := determineStatus(versions)
+		status := comparison.DetermineStatus(versions)
 
 		runtimeComparisons = append(runtimeComparisons, output.RuntimeComparison{
 			Name:     name,
@@ -177,11 +139,9 @@ func buildComparison(serverResults []ServerRuntimes) *output.ComparisonData {
 
 	hosts := make([]string, len(serverResults))
 	for i, server := range serverResults {
-		hostParts := strings.Split(server.Host, ":")
+		hosts[i] = displayHost(server.Host)
 		if server.Error != nil {
-			hosts[i] = hostParts[0] + " (ERR)"
-		} else {
-			hosts[i] = hostParts[0]
+			hosts[i] += " (ERR)"
 		}
 	}
 
@@ -191,42 +151,14 @@ func buildComparison(serverResults []ServerRuntimes) *output.ComparisonData {
 	}
 }
 
-func determineStatus(versions []string) string {
-	if len(versions) == 0 {
-		return "MISSING"
+// displayHost drops the port of host:port addresses for the column headers; names,
+// unix:// sockets and addresses without a port are shown as given
+func displayHost(host string) string {
+	if strings.Contains(host, "://") {
+		return host
 	}
-
-	uniqueVersions := make(map[string]struct{})
-	hasVersion := false
-	hasMissing := false
-
-	for _, version := range versions {
-		switch version {
-		case "ERROR":
-			return "ERROR"
-		case "x":
-			hasMissing = true
-		default:
-			hasVersion = true
-			uniqueVersions[version] = struct{}{}
-		}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
 	}
-
-	if !hasVersion && hasMissing {
-		return "MISSING"
-	}
-
-	if hasMissing {
-		return "PARTIAL"
-	}
-
-	if len(uniqueVersions) > 1 {
-		return "DIFF"
-	}
-
-	if len(uniqueVersions) == 1 {
-		return "SAME"
-	}
-
-	return "MISSING"
+	return host
 }
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	internalconfig "github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var useContextCmd = &cobra.Command{
+	Use:   "use-context <name>",
+	Short: "Set the current context",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUseContext,
+}
+
+var currentContextCmd = &cobra.Command{
+	Use:   "current-context",
+	Short: "Display the current context",
+	Args:  cobra.NoArgs,
+	RunE:  runCurrentContext,
+}
+
+var getContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List all contexts",
+	Args:  cobra.NoArgs,
+	RunE:  runGetContexts,
+}
+
+var setContextCmd = &cobra.Command{
+	Use:   "set-context <name>",
+	Short: "Create or update a context",
+	Long: `Create or update a context. A context selects the default servers
+(a group or a list of servers) and the key used by wctl commands.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSetContext,
+}
+
+func init() {
+	setContextCmd.Flags().String("group", "", "Group of servers targeted by this context")
+	setContextCmd.Flags().StringSlice("servers", []string{}, "Servers targeted by this context (names or addresses)")
+	setContextCmd.Flags().String("key", "", "Key name used by this context")
+	setContextCmd.Flags().String("collector", "", "Fleet collector queried by this context (configured server name or address)")
+}
+
+func runUseContext(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := cfg.Contexts[args[0]]; !ok {
+		return fmt.Errorf("context %q not found", args[0])
+	}
+
+	cfg.CurrentContext = args[0]
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to context %q\n", args[0])
+
+	return nil
+}
+
+func runCurrentContext(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.CurrentContext == "" {
+		return fmt.Errorf("current-context is not set")
+	}
+
+	fmt.Println(cfg.CurrentContext)
+
+	return nil
+}
+
+func runGetContexts(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Contexts) == 0 {
+		fmt.Println("No contexts configured")
+		fmt.Println()
+		fmt.Println("Create one with:")
+		fmt.Println("   wctl config set-context <name> --group <group>")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Current", "Name", "Group", "Servers", "Key", "Collector"})
+
+	for _, name := range cfg.ContextNames() {
+		ctx := cfg.Contexts[name]
+		marker := ""
+		if name == cfg.CurrentContext {
+			marker = "*"
+		}
+		table.Append([]string{marker, name, ctx.Group, strings.Join(ctx.Servers, ", "), ctx.Key, ctx.Collector})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func runSetContext(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Contexts == nil {
+		cfg.Contexts = make(map[string]*internalconfig.Context)
+	}
+
+	ctx, exists := cfg.Contexts[args[0]]
+	if !exists {
+		ctx = &internalconfig.Context{}
+		cfg.Contexts[args[0]] = ctx
+	}
+
+	if cmd.Flags().Changed("group") {
+		ctx.Group, _ = cmd.Flags().GetString("group")
+	}
+	if cmd.Flags().Changed("servers") {
+		ctx.Servers, _ = cmd.Flags().GetStringSlice("servers")
+	}
+	if cmd.Flags().Changed("key") {
+		ctx.Key, _ = cmd.Flags().GetString("key")
+	}
+	if cmd.Flags().Changed("collector") {
+		ctx.Collector, _ = cmd.Flags().GetString("collector")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	if exists {
+		fmt.Printf("Context %q modified\n", args[0])
+	} else {
+		fmt.Printf("Context %q created\n", args[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+)
+
+var setGroupCmd = &cobra.Command{
+	Use:   "set-group <name> <server>...",
+	Short: "Create or replace a group of servers",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runSetGroup,
+}
+
+func runSetGroup(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Groups == nil {
+		cfg.Groups = make(map[string][]string)
+	}
+	cfg.Groups[args[0]] = args[1:]
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Group %q set (%d server(s))\n", args[0], len(args)-1)
+
+	return nil
+}
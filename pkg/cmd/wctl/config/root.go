@@ -0,0 +1,31 @@
+package config
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage client configuration",
+	Long: `Manage servers, groups and contexts in ~/.watcher/config.
+
+Examples:
+  # Register servers and group them
+  wctl config set-server web1 --address web1.prod:9090 --key prod --label role=web
+  wctl config set-server web2 --address web2.prod:9090 --key prod --label role=web
+  wctl config set-group prod-web web1 web2
+
+  # Make the group the default target
+  wctl config set-context prod --group prod-web --key prod
+  wctl config use-context prod
+  wctl compare runtimes`,
+}
+
+func init() {
+	Cmd.AddCommand(useContextCmd)
+	Cmd.AddCommand(currentContextCmd)
+	Cmd.AddCommand(getContextsCmd)
+	Cmd.AddCommand(setContextCmd)
+	Cmd.AddCommand(setServerCmd)
+	Cmd.AddCommand(getServersCmd)
+	Cmd.AddCommand(setGroupCmd)
+	Cmd.AddCommand(viewCmd)
+}
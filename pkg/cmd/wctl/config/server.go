@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	internalconfig "github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var setServerCmd = &cobra.Command{
+	Use:   "set-server <name>",
+	Short: "Create or update a server",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSetServer,
+}
+
+var getServersCmd = &cobra.Command{
+	Use:   "get-servers",
+	Short: "List configured servers",
+	Args:  cobra.NoArgs,
+	RunE:  runGetServers,
+}
+
+func init() {
+	setServerCmd.Flags().String("address", "", "Server address (e.g., server:9090)")
+	setServerCmd.Flags().String("key", "", "Key name used for this server")
+	setServerCmd.Flags().StringToString("label", map[string]string{}, "Labels for this server (key=value)")
+	setServerCmd.Flags().String("tls-ca", "", "CA certificate used to verify the server")
+	setServerCmd.Flags().String("tls-cert", "", "Client certificate for mutual TLS")
+	setServerCmd.Flags().String("tls-key", "", "Client private key for mutual TLS")
+	setServerCmd.Flags().String("tls-server-name", "", "Override the TLS server name")
+	setServerCmd.Flags().Bool("tls-insecure-skip-verify", false, "Skip server certificate verification (testing only)")
+	setServerCmd.Flags().Bool("tls", false, "Connect with TLS using the system CA pool")
+	setServerCmd.Flags().Bool("sign-requests", false, "Sign requests with the key instead of sending it (HMAC)")
+}
+
+func runSetServer(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Servers == nil {
+		cfg.Servers = make(map[string]*internalconfig.Server)
+	}
+
+	server, exists := cfg.Servers[args[0]]
+	if !exists {
+		server = &internalconfig.Server{}
+		cfg.Servers[args[0]] = server
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("address") {
+		server.Address, _ = flags.GetString("address")
+	}
+	if flags.Changed("key") {
+		server.Key, _ = flags.GetString("key")
+	}
+	if flags.Changed("label") {
+		labels, _ := flags.GetStringToString("label")
+		if server.Labels == nil {
+			server.Labels = make(map[string]string)
+		}
+		for k, v := range labels {
+			server.Labels[k] = v
+		}
+	}
+	if flags.Changed("sign-requests") {
+		server.SignRequests, _ = flags.GetBool("sign-requests")
+	}
+
+	tlsFlags := []string{"tls", "tls-ca", "tls-cert", "tls-key", "tls-server-name", "tls-insecure-skip-verify"}
+	for _, name := range tlsFlags {
+		if !flags.Changed(name) {
+			continue
+		}
+		if server.TLS == nil {
+			server.TLS = &internalconfig.TLSConfig{}
+		}
+		switch name {
+		case "tls-ca":
+			server.TLS.CAFile, _ = flags.GetString(name)
+		case "tls-cert":
+			server.TLS.CertFile, _ = flags.GetString(name)
+		case "tls-key":
+			server.TLS.KeyFile, _ = flags.GetString(name)
+		case "tls-server-name":
+			server.TLS.ServerName, _ = flags.GetString(name)
+		case "tls-insecure-skip-verify":
+			server.TLS.InsecureSkipVerify, _ = flags.GetBool(name)
+		case "tls":
+			if enabled, _ := flags.GetBool(name); !enabled {
+				server.TLS = nil
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	if exists {
+		fmt.Printf("Server %q modified\n", args[0])
+	} else {
+		fmt.Printf("Server %q created\n", args[0])
+	}
+
+	return nil
+}
+
+func runGetServers(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Servers) == 0 {
+		fmt.Println("No servers configured")
+		fmt.Println()
+		fmt.Println("Add one with:")
+		fmt.Println("   wctl config set-server <name> --address <host:port>")
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Address", "Key", "TLS", "Labels"})
+
+	for _, name := range names {
+		server := cfg.Servers[name]
+		tlsMode := "-"
+		if server.TLS != nil {
+			tlsMode = "on"
+		}
+		table.Append([]string{name, server.Address, server.Key, tlsMode, formatLabels(server.Labels)})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+)
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Display the client configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runView,
+}
+
+func runView(cmd *cobra.Command, args []string) error {
+	cfg, err := common.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	data, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("# %s\n", cfg.Path())
+	fmt.Print(string(data))
+
+	return nil
+}
@@ -2,37 +2,36 @@ package get
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/spf13/cobra"
 )
 
 var keyCmd = &cobra.Command{
 	Use:   "key",
 	Short: "Get API key",
-	Long:  `Display the current API key`,
+	Long:  `Display the current API key, or a saved key by name`,
 	RunE:  runGetKey,
 }
 
 func init() {
 	Cmd.AddCommand(keyCmd)
+	keyCmd.Flags().String("name", "", "Name of the saved key (default: current key)")
 }
 
 func runGetKey(cmd *cobra.Command, args []string) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
-	}
+	name, _ := cmd.Flags().GetString("name")
 
-	keysDir := filepath.Join(homeDir, ".watcher", "keys")
-	manager, err := keymanager.NewManager(keysDir)
+	manager, err := common.KeyManager()
 	if err != nil {
 		return err
 	}
 
-	apiKey, err := manager.Load(keymanager.DefaultKeyName)
+	if name == "" {
+		name = manager.Current()
+	}
+
+	apiKey, err := manager.Load(name)
 	if err != nil {
 		return fmt.Errorf("no API key found\n\nGenerate a new key with:\n   wctl key gen")
 	}
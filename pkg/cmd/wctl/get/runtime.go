@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/binaryarc/watcher/internal/detector"
-	"github.com/binaryarc/watcher/internal/grpcclient"
 	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/spf13/cobra"
 )
 
@@ -32,8 +32,7 @@ func runGetRuntime(cmd *cobra.Command, args []string) {
 	var err error
 
 	if host != "" {
-		apiKey, _ := cmd.Root().PersistentFlags().GetString("api-key")
-		runtime, err = observeRemoteRuntime(host, apiKey, runtimeName, outputFormat)
+		runtime, err = observeRemoteRuntime(cmd, host, runtimeName, outputFormat)
 		if err != nil {
 			fmt.Printf("Failed to observe remote server: %v\n", err)
 			return
@@ -118,12 +117,12 @@ func observeLocalRuntime(runtimeName string, outputFormat string) (*detector.Run
 	return runtime, nil
 }
 
-func observeRemoteRuntime(host string, apiKey string, runtimeName string, outputFormat string) (*detector.Runtime, error) {
+func observeRemoteRuntime(cmd *cobra.Command, host string, runtimeName string, outputFormat string) (*detector.Runtime, error) {
 	if outputFormat == "table" {
 		fmt.Printf("Connecting to remote server: %s...\n\n", host)
 	}
 
-	client, err := grpcclient.NewClient(host, apiKey)
+	client, err := common.NewClient(cmd, host)
 	if err != nil {
 		return nil, err
 	}
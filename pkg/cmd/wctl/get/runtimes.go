@@ -3,48 +3,93 @@ package get
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/binaryarc/watcher/internal/detector"
-	"github.com/binaryarc/watcher/internal/grpcclient"
+	"github.com/binaryarc/watcher/internal/metrics"
 	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/spf13/cobra"
 )
 
 var runtimesCmd = &cobra.Command{
 	Use:   "runtimes",
 	Short: "Get all detected runtimes",
-	Long:  `Scan and display all detected runtime versions on the system`,
-	Run:   runGetRuntimes,
+	Long: `Scan and display all detected runtime versions on the system.
+
+With --hosts, --group, --inventory, --collector or a label selector (-l) the runtimes
+of several servers are listed together.
+
+-o prom prints the runtimes as Prometheus metrics. --textfile-dir writes them
+atomically to watcher_runtimes.prom in a node_exporter textfile collector
+directory instead, so a cron job can publish runtime versions through node_exporter.
+
+Servers started with --cache-ttl may answer from cached detection results;
+--refresh makes them re-run detection.
+
+Examples:
+  wctl get runtimes
+  wctl get runtimes --host server:9090
+  wctl get runtimes --host server:9090 --refresh
+  wctl get runtimes --inventory hosts.ini --limit web
+  wctl get runtimes -l env=prod,role!=db
+  wctl get runtimes --collector collector:9091
+  wctl get runtimes -o prom
+  wctl get runtimes --textfile-dir /var/lib/node_exporter/textfile_collector`,
+	RunE: runGetRuntimes,
 }
 
 func init() {
 	Cmd.AddCommand(runtimesCmd)
 	runtimesCmd.Flags().String("host", "", "Remote server address (e.g., server:9090)")
+	runtimesCmd.Flags().Bool("refresh", false, "Ask servers to re-run detection instead of answering from their cache")
+	runtimesCmd.Flags().String("textfile-dir", "", "Write Prometheus metrics to this node_exporter textfile collector directory")
+	common.AddTargetFlags(runtimesCmd)
 }
 
-func runGetRuntimes(c *cobra.Command, args []string) {
+func runGetRuntimes(c *cobra.Command, args []string) error {
+	c.SilenceUsage = true
+
 	outputFormat, _ := c.Flags().GetString("output")
 	host, _ := c.Flags().GetString("host")
+	textfileDir, _ := c.Flags().GetString("textfile-dir")
+
+	if textfileDir != "" {
+		outputFormat = "prom"
+	}
+
+	if common.HasTargetFlags(c) {
+		return getRuntimesFromHosts(c, outputFormat, textfileDir)
+	}
 
 	var runtimes []*detector.Runtime
 	var err error
 
+	// watcher_runtime_info의 host 라벨: 원격이면 주소, 로컬이면 hostname
+	metricsHost := host
 	if host != "" {
-		apiKey, _ := c.Root().PersistentFlags().GetString("api-key")
-		runtimes, err = observeRemoteRuntimes(host, apiKey, outputFormat)
+		runtimes, err = observeRemoteRuntimes(c, host, outputFormat)
 		if err != nil {
 			fmt.Printf("Failed to observe remote server: %v\n", err)
-			return
+			return textfileFailed(textfileDir)
 		}
 	} else {
 		runtimes = observeLocalRuntimes(outputFormat)
+		metricsHost, _ = os.Hostname()
+	}
+
+	// 런타임이 없어도 textfile은 갱신해야 이전 버전이 남지 않는다
+	if textfileDir != "" {
+		return writeTextfile(textfileDir, output.RuntimesRegistry(metricsHost, runtimes))
 	}
 
 	if len(runtimes) == 0 {
 		if outputFormat == "table" {
 			fmt.Println("No runtimes detected.")
 		}
-		return
+		return nil
 	}
 
 	switch outputFormat {
@@ -56,13 +101,17 @@ func runGetRuntimes(c *cobra.Command, args []string) {
 		if err := output.PrintRuntimesYAML(runtimes); err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
+	case "prom":
+		output.PrintRuntimesProm(metricsHost, runtimes)
 	case "table":
 		output.PrintRuntimesTable(runtimes)
 		fmt.Printf("\nTotal: %d runtime(s) detected\n", len(runtimes))
 	default:
 		fmt.Printf("Unknown output format: %s\n", outputFormat)
-		fmt.Println("Supported formats: table, json, yaml")
+		fmt.Println("Supported formats: table, json, yaml, prom")
 	}
+
+	return nil
 }
 
 func observeLocalRuntimes(outputFormat string) []*detector.Runtime {
@@ -91,22 +140,116 @@ func observeLocalRuntimes(outputFormat string) []*detector.Runtime {
 	return runtimes
 }
 
-func observeRemoteRuntimes(host string, apiKey string, outputFormat string) ([]*detector.Runtime, error) {
+func observeRemoteRuntimes(cmd *cobra.Command, host string, outputFormat string) ([]*detector.Runtime, error) {
 	if outputFormat == "table" {
 		fmt.Printf("Connecting to remote server: %s...\n\n", host)
 	}
 
-	client, err := grpcclient.NewClient(host, apiKey)
+	client, err := common.NewClient(cmd, host)
 	if err != nil {
 		return nil, err
 	}
 	defer client.Close()
 
 	ctx := context.Background()
-	runtimes, err := client.ObserveRuntimes(ctx)
+	observation, err := client.Observe(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return runtimes, nil
+	if outputFormat == "table" && observation.CacheAge > 0 {
+		fmt.Printf("Cached result from %s ago (use --refresh to re-run detection)\n\n", observation.CacheAge)
+	}
+
+	return observation.Runtimes, nil
+}
+
+func getRuntimesFromHosts(cmd *cobra.Command, outputFormat string, textfileDir string) error {
+	targets, err := common.ResolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return textfileFailed(textfileDir)
+	}
+
+	if len(targets) == 0 && !common.UsesCollector(cmd) {
+		fmt.Println("No servers selected.")
+		return textfileFailed(textfileDir)
+	}
+
+	if outputFormat == "table" && len(targets) > 0 {
+		fmt.Printf("Observing runtimes on %d server(s)...\n\n", len(targets))
+	}
+
+	results, err := common.FetchAllServers(cmd, targets)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return textfileFailed(textfileDir)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matching servers found.")
+		return textfileFailed(textfileDir)
+	}
+
+	hosts := make([]output.HostRuntimes, 0, len(results))
+	for _, result := range results {
+		host := output.HostRuntimes{Host: result.Host, Labels: result.Labels}
+		if result.Error != nil {
+			host.Error = result.Error.Error()
+		} else {
+			host.Runtimes = sortedRuntimes(result.Runtimes)
+		}
+		hosts = append(hosts, host)
+	}
+
+	if textfileDir != "" {
+		return writeTextfile(textfileDir, output.HostRuntimesRegistry(hosts))
+	}
+
+	switch outputFormat {
+	case "json":
+		if err := output.PrintHostRuntimesJSON(hosts); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "yaml":
+		if err := output.PrintHostRuntimesYAML(hosts); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "prom":
+		output.PrintHostRuntimesProm(hosts)
+	case "table":
+		output.PrintHostRuntimesTable(hosts)
+	default:
+		fmt.Printf("Unknown output format: %s\n", outputFormat)
+		fmt.Println("Supported formats: table, json, yaml, prom")
+	}
+
+	return nil
+}
+
+func writeTextfile(dir string, registry *metrics.Registry) error {
+	return metrics.WriteTextfile(filepath.Join(dir, output.TextfileName), registry)
+}
+
+// textfileFailed returns an error with --textfile-dir so wctl exits non-zero and cron
+// reports the failure. The previous file is left as it is; its collected timestamp
+// shows that it went stale.
+func textfileFailed(textfileDir string) error {
+	if textfileDir == "" {
+		return nil
+	}
+	return fmt.Errorf("%s was not updated", filepath.Join(textfileDir, output.TextfileName))
+}
+
+func sortedRuntimes(runtimes map[string]*detector.Runtime) []*detector.Runtime {
+	names := make([]string, 0, len(runtimes))
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sorted := make([]*detector.Runtime, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, runtimes[name])
+	}
+	return sorted
 }
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the timeline of runtime changes on a host",
+	Long: `Show when runtimes appeared, disappeared or changed version on a host.
+
+History is recorded by a fleet collector started with --history-file. The collector
+is taken from --collector or the current context.
+
+Examples:
+  wctl history --host web-12 --runtime java --collector collector:9091
+  wctl history --host web-12 -o json`,
+	Run: runHistory,
+}
+
+func init() {
+	Cmd.Flags().String("host", "", "Agent name or address")
+	Cmd.Flags().String("runtime", "", "Only show changes of this runtime")
+	Cmd.Flags().String("collector", "", "Fleet collector address (default: collector of the current context)")
+	Cmd.MarkFlagRequired("host")
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	outputFormat, _ := cmd.Flags().GetString("output")
+	host, _ := cmd.Flags().GetString("host")
+	runtime, _ := cmd.Flags().GetString("runtime")
+
+	collectorAddr := common.CollectorAddress(cmd)
+	if collectorAddr == "" {
+		fmt.Println("Error: no collector configured")
+		fmt.Println("Use --collector or set one on the context (wctl config set-context <name> --collector <addr>)")
+		return
+	}
+
+	client, err := common.NewClient(cmd, collectorAddr)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer client.Close()
+
+	changes, err := client.History(context.Background(), host, runtime)
+	if err != nil {
+		fmt.Printf("Failed to get history: %v\n", err)
+		return
+	}
+
+	entries := make([]output.HistoryEntry, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, output.NewHistoryEntry(change))
+	}
+
+	switch outputFormat {
+	case "json":
+		if err := output.PrintHistoryJSON(entries); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "yaml":
+		if err := output.PrintHistoryYAML(entries); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "table":
+		if len(entries) == 0 {
+			fmt.Printf("No history recorded for %s\n", host)
+			return
+		}
+		output.PrintHistoryTable(entries)
+	default:
+		fmt.Printf("Unknown output format: %s\n", outputFormat)
+		fmt.Println("Supported formats: table, json, yaml")
+	}
+}
@@ -0,0 +1,59 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var bindCmd = &cobra.Command{
+	Use:   "bind <host-pattern> <name>",
+	Short: "Use a key for matching hosts",
+	Long: `Send the named key to every host matching the pattern.
+
+Patterns are globs matched against the host address and the host name without port.
+The first matching binding wins; hosts without a binding use the current key.
+
+Examples:
+  wctl key bind "prod*" prod
+  wctl key bind "*.stage.example.com" stage`,
+	Args: cobra.ExactArgs(2),
+	RunE: runBind,
+}
+
+var unbindCmd = &cobra.Command{
+	Use:   "unbind <host-pattern>",
+	Short: "Remove a host key binding",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnbind,
+}
+
+func runBind(cmd *cobra.Command, args []string) error {
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Bind(args[0], args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Hosts matching %q will use key %q\n", args[0], args[1])
+
+	return nil
+}
+
+func runUnbind(cmd *cobra.Command, args []string) error {
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Unbind(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed binding for %q\n", args[0])
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved API key",
+	Long:  `Delete a saved API key and the host bindings that refer to it`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelete,
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Delete(name); err != nil {
+		return err
+	}
+
+	bindings, err := manager.Bindings()
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		if binding.Key == name {
+			if err := manager.Unbind(binding.Pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Deleted key %q\n", name)
+
+	return nil
+}
@@ -2,31 +2,30 @@ package key
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/spf13/cobra"
 )
 
 var genCmd = &cobra.Command{
 	Use:   "gen",
 	Short: "Generate a new API key",
-	Long:  `Generate a new API key (replaces existing key)`,
+	Long:  `Generate a new API key (replaces existing key with the same name)`,
 	RunE:  runGenerate,
 }
 
-func getKeyManager() (*keymanager.Manager, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
+func init() {
+	genCmd.Flags().String("name", keymanager.DefaultKeyName, "Name to save the key under")
+}
 
-	keysDir := filepath.Join(homeDir, ".watcher", "keys")
-	return keymanager.NewManager(keysDir)
+func getKeyManager() (*keymanager.Manager, error) {
+	return common.KeyManager()
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+
 	manager, err := getKeyManager()
 	if err != nil {
 		return err
@@ -37,7 +36,7 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate key: %w", err)
 	}
 
-	if err := manager.Save(keymanager.DefaultKeyName, apiKey); err != nil {
+	if err := manager.Save(name, apiKey); err != nil {
 		return fmt.Errorf("failed to save key: %w", err)
 	}
 
@@ -0,0 +1,60 @@
+package key
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <name> [file]",
+	Short: "Import an API key",
+	Long: `Save an existing API key under a name.
+
+The key is read from the file, or from stdin when no file (or "-") is given.
+
+Examples:
+  # Import a key issued for production servers
+  wctl key import prod prod.key
+
+  # Import from a secret manager
+  vault read -field=key secret/watcher/prod | wctl key import prod`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runImport,
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	var data []byte
+	var err error
+	if len(args) < 2 || args[1] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[1])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+
+	apiKey := strings.TrimSpace(string(data))
+	if apiKey == "" {
+		return fmt.Errorf("no API key found in input")
+	}
+
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Save(name, apiKey); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported key %q\n", name)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package key
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved API keys",
+	Long:  `List saved API keys, the current key and the host patterns bound to each key`,
+	RunE:  runList,
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	names, err := manager.List()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No API keys saved")
+		fmt.Println()
+		fmt.Println("Generate a new key with:")
+		fmt.Println("   wctl key gen")
+		return nil
+	}
+
+	bindings, err := manager.Bindings()
+	if err != nil {
+		return err
+	}
+
+	hostsByKey := make(map[string][]string)
+	for _, binding := range bindings {
+		hostsByKey[binding.Key] = append(hostsByKey[binding.Key], binding.Pattern)
+	}
+
+	current := manager.Current()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Current", "Name", "Hosts"})
+
+	for _, name := range names {
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		table.Append([]string{marker, name, strings.Join(hostsByKey[name], ", ")})
+	}
+
+	table.Render()
+
+	return nil
+}
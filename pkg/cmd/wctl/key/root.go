@@ -4,10 +4,17 @@ import "github.com/spf13/cobra"
 
 var Cmd = &cobra.Command{
 	Use:   "key",
-	Short: "Manage API key",
-	Long:  `Generate and view API key for authentication with watcher servers`,
+	Short: "Manage API keys",
+	Long:  `Generate, import and select API keys for authentication with watcher servers`,
 }
 
 func init() {
 	Cmd.AddCommand(genCmd)
+	Cmd.AddCommand(rotateCmd)
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(useCmd)
+	Cmd.AddCommand(deleteCmd)
+	Cmd.AddCommand(importCmd)
+	Cmd.AddCommand(bindCmd)
+	Cmd.AddCommand(unbindCmd)
 }
@@ -0,0 +1,87 @@
+package key
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+)
+
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the API key",
+	Long: `Replace the saved API key with its successor.
+
+With --host the server mints the successor (requires "wsctl run --allow-key-rotation").
+With --key a successor created by "wsctl rotate key" is installed.
+The old key keeps working on the server until its grace period ends.
+
+Examples:
+  # Ask the server for a new key
+  wctl key rotate --host server:9090
+
+  # Install a key rotated on the server
+  wctl key rotate --key watcher_...`,
+	RunE: runRotate,
+}
+
+func init() {
+	rotateCmd.Flags().String("host", "", "Server that mints the successor key (e.g., server:9090)")
+	rotateCmd.Flags().String("key", "", "Successor key to install")
+	rotateCmd.Flags().String("name", "", "Name of the saved key to rotate (default: key bound to --host, or the current key)")
+}
+
+func runRotate(cmd *cobra.Command, args []string) error {
+	host, _ := cmd.Flags().GetString("host")
+	newKey, _ := cmd.Flags().GetString("key")
+	name, _ := cmd.Flags().GetString("name")
+
+	if host == "" && newKey == "" {
+		return fmt.Errorf("either --host or --key is required")
+	}
+
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if bound, ok := manager.KeyNameForHost(host); ok && host != "" {
+			name = bound
+		} else {
+			name = manager.Current()
+		}
+	}
+
+	var expiresAt time.Time
+	if newKey == "" {
+		currentKey, err := manager.Load(name)
+		if err != nil {
+			return err
+		}
+
+		client, err := common.Dial(cmd, common.Target{Name: host, Address: host, APIKey: currentKey})
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		newKey, expiresAt, err = client.RotateKey(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to rotate key: %w", err)
+		}
+	}
+
+	if err := manager.Save(name, newKey); err != nil {
+		return fmt.Errorf("failed to save key: %w", err)
+	}
+
+	fmt.Printf("API key %q rotated\n", name)
+	if !expiresAt.IsZero() {
+		fmt.Printf("Old key valid until: %s\n", expiresAt.Format("2006-01-02 15:04:05"))
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the current API key",
+	Long:  `Use the named key for hosts that have no key bound to them`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUse,
+}
+
+func runUse(cmd *cobra.Command, args []string) error {
+	manager, err := getKeyManager()
+	if err != nil {
+		return err
+	}
+
+	if err := manager.Use(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to key %q\n", args[0])
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package ping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/grpcserver"
+	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Check that servers are reachable and accept the API key",
+	Long: `Check each server with the standard gRPC health service and report its
+latency, then call GetServerInfo to check the API key and show the server version.
+
+Servers are selected like for other multi-host commands (the current context if no
+flags are given). With --collector the collector is checked as well.
+Exits with status 1 if any server is unreachable, not serving or rejects the key.
+
+Examples:
+  wctl ping --hosts server1:9090,server2:9090
+  wctl ping --group production -o json
+  wctl ping -l env=prod`,
+	Run: runPing,
+}
+
+func init() {
+	common.AddTargetFlags(Cmd)
+}
+
+func runPing(cmd *cobra.Command, args []string) {
+	outputFormat, _ := cmd.Flags().GetString("output")
+
+	targets, err := common.ResolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if addr, _ := cmd.Flags().GetString("collector"); addr != "" {
+		targets = append(targets, common.Target{Name: addr, Address: addr})
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No servers selected.")
+		return
+	}
+
+	selector, err := common.Selector(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	pinged := make([]output.PingResult, len(targets))
+	for i, target := range targets {
+		wg.Add(1)
+		go func(index int, target common.Target) {
+			defer wg.Done()
+			pinged[index] = ping(cmd, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	// 도달하지 못한 서버는 설정된 라벨이 있을 때만 selector로 거를 수 있음
+	results := make([]output.PingResult, 0, len(pinged))
+	for i, result := range pinged {
+		if result.Labels == nil && targets[i].Labels == nil {
+			results = append(results, result)
+			continue
+		}
+		if selector.Matches(result.Labels) {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No matching servers found.")
+		return
+	}
+
+	switch outputFormat {
+	case "json":
+		if err := output.PrintPingJSON(results); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "yaml":
+		if err := output.PrintPingYAML(results); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	case "table":
+		output.PrintPingTable(results)
+	default:
+		fmt.Printf("Unknown output format: %s\n", outputFormat)
+		fmt.Println("Supported formats: table, json, yaml")
+		return
+	}
+
+	for _, result := range results {
+		if !result.OK() {
+			os.Exit(1)
+		}
+	}
+}
+
+// ping checks one server: health and latency first (no key needed), then the key
+func ping(cmd *cobra.Command, target common.Target) output.PingResult {
+	result := output.PingResult{Host: target.Name, Labels: target.Labels}
+
+	client, err := common.Dial(cmd, target)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	start := time.Now()
+	health, err := client.CheckHealth(ctx, "")
+	latency := time.Since(start)
+
+	switch status.Code(err) {
+	case codes.OK:
+		result.Health = health
+	case codes.Unimplemented:
+		// 헬스 서비스가 없는 이전 버전 서버도 응답은 했으므로 도달 가능
+		result.Health = "UNKNOWN"
+	default:
+		result.Error = err.Error()
+		return result
+	}
+	result.Reachable = true
+	result.LatencyMS = float64(latency.Microseconds()) / 1000
+
+	info, err := client.GetServerInfo(ctx)
+	switch status.Code(err) {
+	case codes.OK:
+		result.Auth = output.AuthOK
+		if info.AuthMode == grpcserver.AuthModeDisabled {
+			result.Auth = output.AuthDisabled
+		}
+		result.Version = info.Version
+		result.APIVersion = info.APIVersion
+		result.Uptime = info.Uptime.String()
+		if len(info.Labels) > 0 && result.Labels == nil {
+			result.Labels = info.Labels
+		}
+	case codes.Unauthenticated:
+		result.Auth = output.AuthMissing
+	case codes.PermissionDenied:
+		result.Auth = output.AuthDenied
+		result.Error = statusMessage(err)
+	case codes.Unimplemented:
+		result.Auth = output.AuthUnknown
+	default:
+		result.Auth = output.AuthUnknown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// statusMessage returns the server's message without the client's wrapping, e.g. "invalid API key"
+func statusMessage(err error) string {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus().Message()
+	}
+	return err.Error()
+}
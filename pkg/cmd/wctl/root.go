@@ -2,12 +2,15 @@ package wctl
 
 import (
 	"os"
-	"path/filepath"
 
-	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
 	"github.com/binaryarc/watcher/pkg/cmd/wctl/compare"
+	wctlconfig "github.com/binaryarc/watcher/pkg/cmd/wctl/config"
 	"github.com/binaryarc/watcher/pkg/cmd/wctl/get"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/history"
 	"github.com/binaryarc/watcher/pkg/cmd/wctl/key"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/ping"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/watch"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +22,7 @@ var rootCmd = &cobra.Command{
 	Use:               "wctl",
 	Short:             "👁️  Watcher - Observe your infrastructure",
 	Long:              `Watcher is a kubectl-style CLI tool for observing runtime versions and services across your infrastructure.`,
-	PersistentPreRunE: loadAPIKey,
+	PersistentPreRunE: loadConfig,
 }
 
 func Execute() {
@@ -30,47 +33,39 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format (table|json|yaml)")
-	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key for authentication")
+	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key for authentication (overrides configured keys)")
+	rootCmd.PersistentFlags().Bool("sign", false, "Sign requests with the API key (HMAC) instead of sending it")
+	rootCmd.PersistentFlags().StringVar(&common.ConfigPath, "config", "", "Path to config file (env: WATCHER_CONFIG, default: ~/.watcher/config)")
+	rootCmd.PersistentFlags().StringVar(&common.ContextName, "context", "", "Config context to use (default: current-context)")
 
 	rootCmd.AddCommand(get.Cmd)
 	rootCmd.AddCommand(compare.Cmd)
 	rootCmd.AddCommand(key.Cmd)
+	rootCmd.AddCommand(wctlconfig.Cmd)
+	rootCmd.AddCommand(history.Cmd)
+	rootCmd.AddCommand(watch.Cmd)
+	rootCmd.AddCommand(ping.Cmd)
 }
 
-func loadAPIKey(cmd *cobra.Command, args []string) error {
-	if cmd.Parent() != nil && cmd.Parent().Use == "key" {
-		return nil
-	}
-
-	if apiKey != "" {
-		return nil
+// loadConfig reads the client config up front so that a broken file is reported once
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if _, err := common.ClientConfig(); err != nil {
+		return err
 	}
 
-	if envKey := os.Getenv("WATCHER_API_KEY"); envKey != "" {
-		apiKey = envKey
+	// config 명령은 잘못된 current-context를 고칠 수 있어야 함
+	if cmd.Parent() != nil && cmd.Parent().Use == "config" {
 		return nil
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil
-	}
-
-	keysDir := filepath.Join(homeDir, ".watcher", "keys")
-	manager, err := keymanager.NewManager(keysDir)
-	if err != nil {
-		return nil
-	}
-
-	key, err := manager.Load(keymanager.DefaultKeyName)
-	if err != nil {
-		return nil
+	if _, err := common.CurrentContext(); err != nil {
+		return err
 	}
 
-	apiKey = key
 	return nil
 }
 
+// GetAPIKey returns the API key used for hosts without a more specific key
 func GetAPIKey() string {
-	return apiKey
+	return common.APIKeyFor(rootCmd, "")
 }
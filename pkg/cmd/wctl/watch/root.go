@@ -0,0 +1,20 @@
+package watch
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch a server for runtime changes",
+	Long: `Stream runtime changes from a server as they happen.
+
+Examples:
+  # Print changes on a server until interrupted
+  wctl watch runtimes --host server:9090
+
+  # Only java and node, starting with the current state
+  wctl watch runtimes --host server:9090 --runtime java,node --initial`,
+}
+
+func init() {
+	Cmd.AddCommand(runtimesCmd)
+}
@@ -0,0 +1,89 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/grpcclient"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/output"
+	"github.com/binaryarc/watcher/pkg/cmd/wctl/common"
+	"github.com/spf13/cobra"
+)
+
+var runtimesCmd = &cobra.Command{
+	Use:   "runtimes",
+	Short: "Print runtimes as they are added, removed or changed",
+	Long: `Print runtimes as they are added, removed or changed on a server.
+
+The server re-runs detection periodically and as soon as a directory in its PATH
+or a detected binary changes. Press Ctrl+C to stop.`,
+	Run: runWatchRuntimes,
+}
+
+func init() {
+	runtimesCmd.Flags().String("host", "", "Remote server address (e.g., server:9090)")
+	runtimesCmd.Flags().StringSlice("runtime", []string{}, "Only watch these runtimes")
+	runtimesCmd.Flags().Duration("interval", 0, "How often the server re-runs detection (default: server setting)")
+	runtimesCmd.Flags().Bool("initial", false, "Print the runtimes found at the start as added")
+	runtimesCmd.MarkFlagRequired("host")
+}
+
+func runWatchRuntimes(cmd *cobra.Command, args []string) {
+	outputFormat, _ := cmd.Flags().GetString("output")
+	host, _ := cmd.Flags().GetString("host")
+	runtimes, _ := cmd.Flags().GetStringSlice("runtime")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	initial, _ := cmd.Flags().GetBool("initial")
+
+	var print func(output.HistoryEntry) error
+	switch outputFormat {
+	case "json":
+		print = output.PrintHistoryLineJSON
+	case "yaml":
+		print = output.PrintHistoryLineYAML
+	case "table":
+		print = func(entry output.HistoryEntry) error {
+			output.PrintHistoryLine(entry)
+			return nil
+		}
+	default:
+		fmt.Printf("Unknown output format: %s\n", outputFormat)
+		fmt.Println("Supported formats: table, json, yaml")
+		return
+	}
+
+	client, err := common.NewClient(cmd, host)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if outputFormat == "table" {
+		fmt.Printf("Watching runtimes on %s (Ctrl+C to stop)...\n\n", host)
+	}
+
+	opts := grpcclient.WatchOptions{
+		RuntimeFilter:  runtimes,
+		Interval:       interval,
+		IncludeInitial: initial,
+	}
+
+	err = client.WatchRuntimes(ctx, opts, func(change history.Change) error {
+		if change.Timestamp == 0 {
+			change.Timestamp = time.Now().Unix()
+		}
+		return print(output.NewHistoryEntry(change))
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
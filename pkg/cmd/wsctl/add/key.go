@@ -2,7 +2,9 @@ package add
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/binaryarc/watcher/internal/auth"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
 	"github.com/spf13/cobra"
 )
@@ -10,9 +12,31 @@ import (
 var keyCmd = &cobra.Command{
 	Use:   "key <api-key> [description]",
 	Short: "Add a new API key",
-	Long:  `Add a new API key to allow clients to authenticate`,
-	Args:  cobra.RangeArgs(1, 2),
-	RunE:  runAddKey,
+	Long: `Add a new API key to allow clients to authenticate.
+
+Scopes restrict what the key may access. Without scopes the key is unrestricted,
+except that only keys with a push: scope may push observations to a collector.
+A key with push: scopes and no rpc: scope can do nothing but push.
+
+Examples:
+  # Key that can only observe java and python
+  wsctl add key <api-key> "Audit tool" --scope runtime:java,runtime:python
+
+  # Key that can only call a single RPC
+  wsctl add key <api-key> "Probe" --scope rpc:ObserveRuntimes
+
+  # Agent key on a collector that may only push its own host
+  wsctl add key <api-key> "web-12 agent" --scope push:web-12`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runAddKey,
+}
+
+var (
+	scopes []string
+)
+
+func init() {
+	keyCmd.Flags().StringSliceVar(&scopes, "scope", []string{}, "Restrict the key to runtimes or RPCs, or let it push for hosts (runtime:<name>, rpc:<method>, push:<host pattern>)")
 }
 
 func runAddKey(cmd *cobra.Command, args []string) error {
@@ -22,12 +46,19 @@ func runAddKey(cmd *cobra.Command, args []string) error {
 		description = args[1]
 	}
 
+	for i, scope := range scopes {
+		scopes[i] = strings.TrimSpace(scope)
+	}
+	if _, err := auth.ParseScopes(scopes); err != nil {
+		return err
+	}
+
 	store, err := common.KeyStore()
 	if err != nil {
 		return err
 	}
 
-	if err := store.Add(apiKey, description); err != nil {
+	if err := store.Add(apiKey, description, scopes); err != nil {
 		return fmt.Errorf("failed to add key: %w", err)
 	}
 
@@ -35,7 +66,11 @@ func runAddKey(cmd *cobra.Command, args []string) error {
 	if description != "" {
 		fmt.Printf("Description: %s\n", description)
 	}
-	fmt.Printf("Key: %s\n", common.MaskKey(apiKey))
+	if len(scopes) > 0 {
+		fmt.Printf("Scopes: %s\n", strings.Join(scopes, ", "))
+	}
+	fmt.Printf("Key: %s\n", auth.MaskKey(apiKey))
+	fmt.Printf("Key ID: %s\n", auth.KeyID(apiKey))
 
 	return nil
 }
@@ -0,0 +1,295 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/collector"
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/inventory"
+	"github.com/binaryarc/watcher/internal/notify"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/binaryarc/watcher/proto"
+	"github.com/spf13/cobra"
+	grpcLib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "collector",
+	Short: "Start a fleet collector",
+	Long: `Start a collector that polls watcher agents and serves fleet-wide queries.
+
+The collector keeps the latest observation of every agent, so clients can compare
+a whole fleet in one round trip:
+
+  wsctl collector --inventory hosts.ini --agent-key-file /etc/watcher/agent.key
+  wctl compare runtimes --collector collector:9091 --group prod`,
+	RunE: runCollector,
+}
+
+var (
+	port           int
+	host           string
+	disableAuth    bool
+	reloadInterval time.Duration
+	inventoryPath  string
+	limit          string
+	agents         []string
+	agentKeyFile   string
+	pollInterval   time.Duration
+	concurrency    int
+	historyFile    string
+	historyKeep    time.Duration
+	tlsCert        string
+	tlsKey         string
+	tlsClientCA    string
+	agentTLS       bool
+	agentSign      bool
+	agentTLSConfig config.TLSConfig
+)
+
+func init() {
+	Cmd.Flags().IntVarP(&port, "port", "p", 9091, "Port to listen on")
+	Cmd.Flags().StringVar(&host, "host", "0.0.0.0", "Host to bind to")
+	Cmd.Flags().BoolVar(&disableAuth, "disable-auth", false, "Disable authentication (use for testing only)")
+	Cmd.Flags().DurationVar(&reloadInterval, "reload-interval", 2*time.Second, "How often to check the keystore file for changes (0 disables; SIGHUP always reloads)")
+	Cmd.Flags().StringVar(&inventoryPath, "inventory", "", "Inventory of agents to poll (Ansible INI/YAML, SSH config, or plain host list)")
+	Cmd.Flags().StringVar(&limit, "limit", "", "Limit inventory hosts by group or host pattern")
+	Cmd.Flags().StringSliceVar(&agents, "agents", []string{}, "Comma-separated list of agent addresses to poll")
+	Cmd.Flags().StringVar(&agentKeyFile, "agent-key-file", "", "File with the API key used to call agents (env: WATCHER_AGENT_KEY)")
+	Cmd.Flags().DurationVar(&pollInterval, "poll-interval", time.Minute, "How often to poll agents")
+	Cmd.Flags().IntVar(&concurrency, "poll-concurrency", collector.DefaultConcurrency, "How many agents to poll at the same time")
+	Cmd.Flags().StringVar(&historyFile, "history-file", "", "Append-only log recording every distinct observation (enables wctl history)")
+	Cmd.Flags().DurationVar(&historyKeep, "history-retention", 90*24*time.Hour, "Drop history entries older than this (0 keeps everything)")
+	Cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Collector certificate; enables TLS (default: tls.cert-file of the config file)")
+	Cmd.Flags().StringVar(&tlsKey, "tls-key", "", "Collector private key")
+	Cmd.Flags().StringVar(&tlsClientCA, "tls-client-ca", "", "Require client certificates signed by this CA (mutual TLS)")
+	Cmd.Flags().BoolVar(&agentTLS, "agent-tls", false, "Connect to agents with TLS using the system CA pool")
+	Cmd.Flags().StringVar(&agentTLSConfig.CAFile, "agent-tls-ca", "", "CA certificate used to verify agents; enables TLS to agents")
+	Cmd.Flags().StringVar(&agentTLSConfig.CertFile, "agent-tls-cert", "", "Client certificate presented to agents (mutual TLS)")
+	Cmd.Flags().StringVar(&agentTLSConfig.KeyFile, "agent-tls-key", "", "Client private key presented to agents")
+	Cmd.Flags().StringVar(&agentTLSConfig.ServerName, "agent-tls-server-name", "", "Override the TLS server name of agents")
+	Cmd.Flags().BoolVar(&agentSign, "agent-sign-requests", false, "Sign requests to agents with the agent key (HMAC) instead of sending it; required by agents in hmac auth mode")
+}
+
+func runCollector(cmd *cobra.Command, args []string) error {
+	addr := fmt.Sprintf("%s:%d", host, port)
+
+	if limit != "" && inventoryPath == "" {
+		return fmt.Errorf("--limit requires --inventory")
+	}
+
+	cfg, err := common.Config()
+	if err != nil {
+		return err
+	}
+	closeLog, err := common.SetupLogging(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	pollAgents, err := resolveAgents()
+	if err != nil {
+		return err
+	}
+
+	agentKey, err := resolveAgentKey()
+	if err != nil {
+		return err
+	}
+
+	serverTLS, err := resolveServerTLS(cmd)
+	if err != nil {
+		return err
+	}
+
+	var agentTLSClient *tls.Config
+	if agentTLS || agentTLSConfig != (config.TLSConfig{}) {
+		agentTLSClient, err = agentTLSConfig.ClientTLS()
+		if err != nil {
+			return fmt.Errorf("invalid agent TLS settings: %w", err)
+		}
+	}
+
+	var grpcOpts []grpcLib.ServerOption
+	if serverTLS != nil {
+		grpcOpts = append(grpcOpts, grpcLib.Creds(credentials.NewTLS(serverTLS)))
+	}
+
+	var grpcServer *grpcLib.Server
+	if disableAuth {
+		slog.Warn("authentication disabled - not recommended for production")
+		grpcServer = grpcLib.NewServer(grpcOpts...)
+	} else {
+		store, err := common.KeyStore()
+		if err != nil {
+			return fmt.Errorf("failed to load keystore: %w", err)
+		}
+
+		if store.IsEmpty() {
+			slog.Warn("no API keys registered - all requests will be rejected (add keys with: wsctl add key)")
+		} else {
+			slog.Info("authentication enabled", "keys", len(store.List()))
+		}
+
+		// 서명된 요청(wsctl push --sign-requests)도 받음
+		signatures := auth.WithSignatures(auth.NewSignatureVerifier(store, 0), false)
+		grpcServer = grpcLib.NewServer(append(grpcOpts,
+			grpcLib.UnaryInterceptor(auth.UnaryServerInterceptor(store, signatures)),
+			grpcLib.StreamInterceptor(auth.StreamServerInterceptor(store, signatures)),
+		)...)
+
+		common.WatchKeyStore(store, reloadInterval)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	var storeOpts []collector.StoreOption
+	if historyFile != "" {
+		h, err := history.Open(historyFile, history.WithRetention(historyKeep))
+		if err != nil {
+			return err
+		}
+		defer h.Close()
+
+		storeOpts = append(storeOpts, collector.WithHistory(h, func(err error) {
+			slog.Error("failed to record history", "error", err)
+		}))
+		slog.Info("recording history", "file", historyFile, "retention", historyKeep)
+	}
+
+	dispatcher, err := common.Notifications()
+	if err != nil {
+		return err
+	}
+	if dispatcher != nil {
+		storeOpts = append(storeOpts, collector.WithChangeHandler(func(host string, hostLabels map[string]string, changes []history.Change) {
+			dispatcher.Dispatch(notify.RuntimeEvents(host, hostLabels, changes)...)
+		}))
+		slog.Info("notifications enabled", "notifiers", strings.Join(dispatcher.Names(), ","))
+	}
+
+	reports := collector.NewStore(storeOpts...)
+
+	if dispatcher != nil {
+		if selectors := dispatcher.StatusSelectors(); len(selectors) > 0 {
+			monitor := &collector.DriftMonitor{
+				Store:     reports,
+				Selectors: selectors,
+				Interval:  pollInterval,
+				OnChange: func(events []notify.Event) {
+					dispatcher.Dispatch(events...)
+				},
+			}
+			go monitor.Run(context.Background())
+		}
+	}
+	proto.RegisterCollectorServiceServer(grpcServer, collector.NewServer(reports))
+	common.RegisterHealth(grpcServer, proto.CollectorService_ServiceDesc.ServiceName)
+	reflection.Register(grpcServer)
+
+	if len(pollAgents) > 0 {
+		poller := &collector.Poller{
+			Store:        reports,
+			Agents:       pollAgents,
+			APIKey:       agentKey,
+			TLS:          agentTLSClient,
+			SignRequests: agentSign,
+			Interval:     pollInterval,
+			Concurrency:  concurrency,
+			OnPoll: func(total, failed int) {
+				if failed > 0 {
+					slog.Warn("failed to poll agents", "agents", total, "failed", failed)
+				}
+			},
+		}
+		go poller.Run(context.Background())
+		slog.Info("polling agents", "agents", len(pollAgents), "interval", pollInterval)
+	}
+
+	slog.Info("watcher collector listening", "address", addr, "tls", serverTLS != nil)
+
+	if err := grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// resolveAgents returns the agents given with --agents and --inventory
+func resolveAgents() ([]collector.Agent, error) {
+	var result []collector.Agent
+
+	for _, agent := range agents {
+		if agent = strings.TrimSpace(agent); agent != "" {
+			result = append(result, collector.Agent{Name: agent, Address: agent})
+		}
+	}
+
+	if inventoryPath != "" {
+		inv, err := inventory.Load(inventoryPath)
+		if err != nil {
+			return nil, err
+		}
+
+		hosts, err := inv.Select(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range hosts {
+			result = append(result, collector.Agent{Name: h.Name, Address: h.Target()})
+		}
+	}
+
+	return result, nil
+}
+
+// resolveServerTLS builds the collector's TLS settings from the --tls-* flags, falling
+// back to the tls section of the config file
+func resolveServerTLS(cmd *cobra.Command) (*tls.Config, error) {
+	settings := config.ServerTLS{CertFile: tlsCert, KeyFile: tlsKey, ClientCAFile: tlsClientCA}
+
+	cfg, err := common.Config()
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.Flags().Changed("tls-cert") && !cmd.Flags().Changed("tls-key") && cfg.TLS.Enabled() {
+		settings.CertFile, settings.KeyFile = cfg.TLS.CertFile, cfg.TLS.KeyFile
+	}
+	if !cmd.Flags().Changed("tls-client-ca") && cfg.TLS.ClientCAFile != "" {
+		settings.ClientCAFile = cfg.TLS.ClientCAFile
+	}
+
+	tlsConfig, err := common.ServerTLS(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	return tlsConfig, nil
+}
+
+// resolveAgentKey reads the key used to call agents from --agent-key-file or WATCHER_AGENT_KEY
+func resolveAgentKey() (string, error) {
+	if agentKeyFile != "" {
+		data, err := os.ReadFile(agentKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read agent key: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	return os.Getenv("WATCHER_AGENT_KEY"), nil
+}
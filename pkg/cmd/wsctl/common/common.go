@@ -1,31 +1,275 @@
 package common
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/detector"
 	"github.com/binaryarc/watcher/internal/keystore"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/binaryarc/watcher/internal/notify"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-func KeyStore() (*keystore.Store, error) {
+// Values of the persistent flags on the wsctl root command
+var (
+	ConfigPath   string
+	KeystorePath string
+)
+
+var loadedConfig *config.ServerConfig
+
+// Config returns the wsctl configuration file (--config, default /etc/watcher/wsctl.yaml)
+func Config() (*config.ServerConfig, error) {
+	if loadedConfig != nil {
+		return loadedConfig, nil
+	}
+
+	path := ConfigPath
+	required := path != ""
+	if path == "" {
+		path = config.DefaultServerConfigPath
+	}
+
+	cfg, err := config.LoadServer(path, required)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedConfig = cfg
+	return cfg, nil
+}
+
+// KeyStorePath resolves the keystore file in order:
+// --keystore flag, WATCHER_KEYSTORE environment variable or config file, ~/.watcher/server/keys.json
+func KeyStorePath() (string, error) {
+	if KeystorePath != "" {
+		return KeystorePath, nil
+	}
+
+	cfg, err := Config()
+	if err != nil {
+		return "", err
+	}
+	if cfg.Keystore != "" {
+		return cfg.Keystore, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".watcher", "server", "keys.json"), nil
+}
+
+// Labels merges the labels of the config file with key=value pairs from --label flags
+func Labels(pairs []string) (map[string]string, error) {
+	cfg, err := Config()
+	if err != nil {
+		return nil, err
+	}
+
+	flagLabels, err := labels.Parse(pairs)
+	if err != nil {
+		return nil, err
 	}
 
-	keysDir := filepath.Join(homeDir, ".watcher", "server")
-	if err := os.MkdirAll(keysDir, 0700); err != nil {
+	merged := make(map[string]string, len(cfg.Labels)+len(flagLabels))
+	for k, v := range cfg.Labels {
+		merged[k] = v
+	}
+	for k, v := range flagLabels {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+// Notifications builds the notifiers defined in the config file, or returns nil if there are none
+func Notifications() (*notify.Dispatcher, error) {
+	cfg, err := Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Notifications) == 0 {
+		return nil, nil
+	}
+
+	return notify.NewDispatcher(cfg.Notifications, func(name string, err error) {
+		slog.Error("failed to send notification", "notifier", name, "error", err)
+	})
+}
+
+func KeyStore() (*keystore.Store, error) {
+	keystorePath, err := KeyStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keystorePath), 0700); err != nil {
 		return nil, fmt.Errorf("failed to create keys directory: %w", err)
 	}
 
-	keystorePath := filepath.Join(keysDir, "keys.json")
 	return keystore.NewStore(keystorePath)
 }
 
-func MaskKey(key string) string {
-	if len(key) <= 14 {
-		return key
+// WatchKeyStore reloads the keystore on SIGHUP and whenever the file changes on disk
+// (checked every interval, 0 disables), so keys added with wsctl take effect without
+// restarting the server
+func WatchKeyStore(store *keystore.Store, interval time.Duration) {
+	onReload := func(err error) {
+		if err != nil {
+			slog.Error("failed to reload keystore", "error", err)
+			return
+		}
+		slog.Info("keystore reloaded", "keys", len(store.List()))
+	}
+
+	if interval > 0 {
+		go store.Watch(context.Background(), interval, onReload)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			onReload(store.Reload())
+		}
+	}()
+}
+
+// RegisterHealth serves the standard grpc.health.v1 service, reporting the server and
+// each of services as SERVING. The returned server can mark them NOT_SERVING.
+func RegisterHealth(server *grpc.Server, services ...string) *health.Server {
+	healthServer := health.NewServer()
+	for _, service := range services {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(server, healthServer)
+	return healthServer
+}
+
+// WritePIDFile writes the process ID to path and returns a function removing it again
+func WritePIDFile(path string) (func(), error) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(pid+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pidfile: %w", err)
 	}
-	return key[:10] + "..." + key[len(key)-4:]
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write pidfile: %w", err)
+	}
+
+	return func() {
+		// 다른 프로세스가 덮어쓴 pidfile은 지우지 않음
+		if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) == pid {
+			os.Remove(path)
+		}
+	}, nil
+}
+
+// Detectors returns the detectors enabled in the config file and applies its detection timeout
+func Detectors() ([]detector.Detector, error) {
+	cfg, err := Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Detectors.Timeout > 0 {
+		detector.SetTimeout(cfg.Detectors.Timeout)
+	}
+
+	selected, err := detector.Select(cfg.Detectors.Enabled, cfg.Detectors.Disabled)
+	if err != nil {
+		return nil, fmt.Errorf("detectors: %w", err)
+	}
+	return selected, nil
+}
+
+// SetupLogging points the default slog logger at the level, format and file of the
+// logging section. The returned function closes the log file.
+func SetupLogging(settings config.LoggingConfig) (func(), error) {
+	var level slog.Level
+	if settings.Level != "" {
+		if err := level.UnmarshalText([]byte(settings.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q", settings.Level)
+		}
+	}
+
+	out := io.Writer(os.Stderr)
+	closeLog := func() {}
+	if settings.File != "" {
+		f, err := os.OpenFile(settings.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = f
+		closeLog = func() { f.Close() }
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch settings.Format {
+	case "", "text":
+		handler = slog.NewTextHandler(out, opts)
+	case "json":
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		closeLog()
+		return nil, fmt.Errorf("invalid log format %q (expected text or json)", settings.Format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return closeLog, nil
+}
+
+// ServerTLS loads the server certificate and, with a client CA, requires client certificates.
+// It returns nil if TLS is not configured.
+func ServerTLS(settings config.ServerTLS) (*tls.Config, error) {
+	if !settings.Enabled() {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if settings.ClientCAFile != "" {
+		pem, err := os.ReadFile(settings.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", settings.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
 }
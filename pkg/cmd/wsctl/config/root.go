@@ -0,0 +1,13 @@
+package config
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the server configuration",
+	Long:  `Check the wsctl config file and WATCHER_* environment overrides`,
+}
+
+func init() {
+	Cmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file and environment overrides",
+	Long: `Load the config file (--config, default /etc/watcher/wsctl.yaml) with its WATCHER_*
+environment overrides and check every setting wsctl run would use: addresses, detectors,
+TLS certificates, logging, labels and notifications.
+
+Examples:
+  wsctl config validate
+  wsctl config validate --config ./wsctl.yaml
+  WATCHER_DETECTORS_DISABLED=docker wsctl config validate`,
+	Args: cobra.NoArgs,
+	RunE: runValidate,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	path := common.ConfigPath
+	if path == "" {
+		path = config.DefaultServerConfigPath
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("Config file: %s\n", path)
+	} else if common.ConfigPath == "" {
+		fmt.Printf("Config file: %s (not found, using defaults)\n", path)
+	}
+
+	cfg, err := common.Config()
+	if err != nil {
+		return err
+	}
+
+	var overrides []string
+	for _, name := range config.EnvNames() {
+		if _, ok := os.LookupEnv(name); ok {
+			overrides = append(overrides, name)
+		}
+	}
+	if len(overrides) > 0 {
+		fmt.Printf("Environment overrides: %s\n", strings.Join(overrides, ", "))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	detectors, err := common.Detectors()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(detectors))
+	for _, det := range detectors {
+		names = append(names, det.Name())
+	}
+	fmt.Printf("Detectors: %s\n", strings.Join(names, ", "))
+
+	if _, err := auth.NewGuard(auth.GuardConfig{Exempt: cfg.Auth.LockoutExemptCIDRs, Deny: cfg.Auth.DenyCIDRs}); err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+
+	if _, err := common.ServerTLS(cfg.TLS); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	if _, err := common.Notifications(); err != nil {
+		return fmt.Errorf("notifications: %w", err)
+	}
+
+	fmt.Println("Configuration is valid")
+	return nil
+}
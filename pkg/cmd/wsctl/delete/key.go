@@ -3,6 +3,7 @@ package delete
 import (
 	"fmt"
 
+	"github.com/binaryarc/watcher/internal/auth"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
 	"github.com/spf13/cobra"
 )
@@ -34,7 +35,7 @@ func runDeleteKey(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println("API key removed successfully")
-	fmt.Printf("Key: %s\n", common.MaskKey(keyName))
+	fmt.Printf("Key: %s\n", auth.MaskKey(keyName))
 
 	return nil
 }
@@ -3,7 +3,10 @@ package get
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/binaryarc/watcher/internal/auth"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -33,13 +36,30 @@ func runGetKeys(cmd *cobra.Command, args []string) error {
 	}
 
 	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Key (masked)", "Description", "Created At"})
+	table.Header([]string{"Key (masked)", "Key ID", "Description", "Scopes", "Created At", "Expires At"})
 
+	now := time.Now()
 	for _, keyInfo := range keys {
+		expires := "-"
+		if keyInfo.ExpiresAt != nil {
+			expires = keyInfo.ExpiresAt.Format("2006-01-02 15:04:05")
+			if keyInfo.IsExpired(now) {
+				expires += " (expired)"
+			}
+		}
+
+		scopes := "*"
+		if len(keyInfo.Scopes) > 0 {
+			scopes = strings.Join(keyInfo.Scopes, ", ")
+		}
+
 		table.Append([]string{
-			common.MaskKey(keyInfo.Key),
+			auth.MaskKey(keyInfo.Key),
+			auth.KeyID(keyInfo.Key),
 			keyInfo.Description,
+			scopes,
 			keyInfo.CreatedAt.Format("2006-01-02 15:04:05"),
+			expires,
 		})
 	}
 
@@ -0,0 +1,52 @@
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export all API keys",
+	Long: `Export all registered API keys as JSON.
+
+The output contains the full keys; treat it like the keystore itself.
+Without a file argument (or with "-") the keys are written to stdout.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	store, err := common.KeyStore()
+	if err != nil {
+		return err
+	}
+
+	keys := store.List()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keys: %w", err)
+	}
+
+	if len(args) == 0 || args[0] == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if err := os.WriteFile(args[0], append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	fmt.Printf("Exported %d API key(s) to %s\n", len(keys), args[0])
+
+	return nil
+}
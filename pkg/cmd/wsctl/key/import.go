@@ -0,0 +1,76 @@
+package key
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/keystore"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/spf13/cobra"
+)
+
+var (
+	overwrite bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import API keys",
+	Long: `Import API keys from a file produced by "wsctl key export".
+
+Use "-" to read from stdin. Keys that already exist are skipped unless --overwrite is set.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace keys that already exist")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	var keys []*keystore.KeyInfo
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse import file: %w", err)
+	}
+
+	for _, info := range keys {
+		if info == nil {
+			continue
+		}
+		if _, err := auth.ParseScopes(info.Scopes); err != nil {
+			return fmt.Errorf("key %s: %w", auth.MaskKey(info.Key), err)
+		}
+	}
+
+	store, err := common.KeyStore()
+	if err != nil {
+		return err
+	}
+
+	added, err := store.Import(keys, overwrite)
+	if err != nil {
+		return fmt.Errorf("failed to import keys: %w", err)
+	}
+
+	fmt.Printf("Imported %d of %d API key(s)\n", added, len(keys))
+	if skipped := len(keys) - added; skipped > 0 {
+		fmt.Printf("Skipped %d existing key(s) (use --overwrite to replace)\n", skipped)
+	}
+
+	return nil
+}
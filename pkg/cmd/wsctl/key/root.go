@@ -0,0 +1,14 @@
+package key
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "key",
+	Short: "Import or export API keys",
+	Long:  `Import and export API keys for provisioning servers`,
+}
+
+func init() {
+	Cmd.AddCommand(importCmd)
+	Cmd.AddCommand(exportCmd)
+}
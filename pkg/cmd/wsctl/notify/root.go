@@ -0,0 +1,13 @@
+package notify
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notifications",
+	Long:  `Test the notifications defined in the config file`,
+}
+
+func init() {
+	Cmd.AddCommand(testCmd)
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/comparison"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/notify"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [name]",
+	Short: "Send a sample event to the configured notifications",
+	Long: `Send a sample event to every notification in the config file, or only to the named one.
+Triggers are ignored so each notifier can be checked.
+
+Examples:
+  wsctl notify test
+  wsctl notify test ops-slack --event status_changed`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTest,
+}
+
+var eventType string
+
+func init() {
+	testCmd.Flags().StringVar(&eventType, "event", notify.RuntimeChanged, "Event to send (runtime_changed or status_changed)")
+}
+
+func runTest(cmd *cobra.Command, args []string) error {
+	dispatcher, err := common.Notifications()
+	if err != nil {
+		return err
+	}
+	if dispatcher == nil {
+		return fmt.Errorf("no notifications configured")
+	}
+
+	event, err := sampleEvent(eventType)
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	results, err := dispatcher.Send(context.Background(), name, event)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(results))
+	for n := range results {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	failed := 0
+	for _, n := range names {
+		if err := results[n]; err != nil {
+			fmt.Printf("%s: FAILED: %v\n", n, err)
+			failed++
+		} else {
+			fmt.Printf("%s: sent\n", n)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d notification(s) failed", failed)
+	}
+	return nil
+}
+
+func sampleEvent(eventType string) (notify.Event, error) {
+	hostname, _ := os.Hostname()
+
+	switch eventType {
+	case notify.RuntimeChanged:
+		return notify.Event{
+			Type:            notify.RuntimeChanged,
+			Timestamp:       time.Now(),
+			Runtime:         "java",
+			Host:            hostname,
+			Change:          history.Changed,
+			PreviousVersion: "17.0.8",
+			Version:         "17.0.9",
+		}, nil
+	case notify.StatusChanged:
+		return notify.Event{
+			Type:           notify.StatusChanged,
+			Timestamp:      time.Now(),
+			Runtime:        "java",
+			PreviousStatus: comparison.StatusSame,
+			Status:         comparison.StatusDiff,
+			Versions:       map[string]string{hostname: "17.0.9", "example-host": "17.0.8"},
+		}, nil
+	default:
+		return notify.Event{}, fmt.Errorf("unknown event %q (expected %s or %s)", eventType, notify.RuntimeChanged, notify.StatusChanged)
+	}
+}
@@ -0,0 +1,152 @@
+package push
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/collector"
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/grpcserver"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/binaryarc/watcher/proto"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push observations to a fleet collector",
+	Long: `Run the detectors locally and push the results to a collector (wsctl collector).
+
+Use this on hosts that can dial out but don't accept inbound connections.
+The agent authenticates with its own API key, which must be registered on the collector
+with a push scope for this host, e.g. --scope push:web-12.
+
+Example:
+  wsctl push --collector collector:9091 --interval 5m --key-file /etc/watcher/agent.key`,
+	RunE: runPush,
+}
+
+var (
+	collectorAddr string
+	interval      time.Duration
+	name          string
+	keyFile       string
+	labelFlags    []string
+	maxBackoff    time.Duration
+	signRequests  bool
+	useTLS        bool
+	tlsConfig     config.TLSConfig
+)
+
+func init() {
+	Cmd.Flags().StringVar(&collectorAddr, "collector", "", "Collector address (e.g., collector:9091)")
+	Cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "How often to push observations")
+	Cmd.Flags().StringVar(&name, "name", "", "Name reported to the collector (default: hostname)")
+	Cmd.Flags().StringVar(&keyFile, "key-file", "", "File with this agent's API key (env: WATCHER_API_KEY)")
+	Cmd.Flags().BoolVar(&signRequests, "sign-requests", false, "Sign pushes with the key (HMAC) instead of sending it")
+	Cmd.Flags().BoolVar(&useTLS, "tls", false, "Connect to the collector with TLS using the system CA pool")
+	Cmd.Flags().StringVar(&tlsConfig.CAFile, "tls-ca", "", "CA certificate used to verify the collector; enables TLS")
+	Cmd.Flags().StringVar(&tlsConfig.CertFile, "tls-cert", "", "Client certificate presented to the collector (mutual TLS)")
+	Cmd.Flags().StringVar(&tlsConfig.KeyFile, "tls-key", "", "Client private key presented to the collector")
+	Cmd.Flags().StringVar(&tlsConfig.ServerName, "tls-server-name", "", "Override the TLS server name of the collector")
+	Cmd.Flags().StringArrayVar(&labelFlags, "label", []string{}, "Label reported to the collector, as key=value (repeatable; overrides labels in the config file)")
+	Cmd.Flags().DurationVar(&maxBackoff, "max-backoff", collector.DefaultMaxBackoff, "Maximum delay between reconnection attempts")
+	Cmd.MarkFlagRequired("collector")
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	cfg, err := common.Config()
+	if err != nil {
+		return err
+	}
+	closeLog, err := common.SetupLogging(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	apiKey, err := resolveKey()
+	if err != nil {
+		return err
+	}
+	if apiKey == "" {
+		slog.Warn("no API key configured - the collector will reject pushes unless auth is disabled")
+	}
+
+	var collectorTLS *tls.Config
+	if useTLS || tlsConfig != (config.TLSConfig{}) {
+		collectorTLS, err = tlsConfig.ClientTLS()
+		if err != nil {
+			return fmt.Errorf("invalid TLS settings: %w", err)
+		}
+	}
+
+	agentLabels, err := common.Labels(labelFlags)
+	if err != nil {
+		return fmt.Errorf("failed to parse labels: %w", err)
+	}
+
+	detectors, err := common.Detectors()
+	if err != nil {
+		return err
+	}
+
+	serverOpts := []grpcserver.Option{grpcserver.WithDetectors(detectors)}
+	if len(agentLabels) > 0 {
+		serverOpts = append(serverOpts, grpcserver.WithLabels(agentLabels))
+		slog.Info("agent labels", "labels", labels.Format(agentLabels))
+	}
+	watcherServer := grpcserver.NewWatcherServer(serverOpts...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pusher := &collector.Pusher{
+		Collector:    collectorAddr,
+		APIKey:       apiKey,
+		TLS:          collectorTLS,
+		SignRequests: signRequests,
+		Host:         name,
+		Interval:     interval,
+		MaxBackoff:   maxBackoff,
+		Observe: func(ctx context.Context) (*proto.ObserveResponse, error) {
+			return watcherServer.ObserveRuntimes(ctx, &proto.ObserveRequest{})
+		},
+		OnPush: func() {
+			slog.Info("pushed observation", "collector", collectorAddr)
+		},
+		OnError: func(err error, retryIn time.Duration) {
+			slog.Warn("failed to push observations", "collector", collectorAddr, "error", err, "retry_in", retryIn.Round(time.Second))
+		},
+	}
+
+	slog.Info("pushing observations", "collector", collectorAddr, "interval", interval, "tls", collectorTLS != nil)
+	pusher.Run(ctx)
+
+	return nil
+}
+
+// resolveKey reads this agent's API key from --key-file or WATCHER_API_KEY
+func resolveKey() (string, error) {
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	return os.Getenv("WATCHER_API_KEY"), nil
+}
@@ -5,8 +5,15 @@ import (
 
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/add"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/clear"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/collector"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/config"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/delete"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/get"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/key"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/notify"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/push"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/rotate"
 	"github.com/binaryarc/watcher/pkg/cmd/wsctl/run"
 	"github.com/spf13/cobra"
 )
@@ -24,9 +31,18 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&common.ConfigPath, "config", "", "Path to config file (default: /etc/watcher/wsctl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&common.KeystorePath, "keystore", "", "Path to keystore file (env: WATCHER_KEYSTORE, default: ~/.watcher/server/keys.json)")
+
 	rootCmd.AddCommand(run.Cmd)
 	rootCmd.AddCommand(get.Cmd)
 	rootCmd.AddCommand(add.Cmd)
 	rootCmd.AddCommand(delete.Cmd)
 	rootCmd.AddCommand(clear.Cmd)
+	rootCmd.AddCommand(key.Cmd)
+	rootCmd.AddCommand(rotate.Cmd)
+	rootCmd.AddCommand(collector.Cmd)
+	rootCmd.AddCommand(push.Cmd)
+	rootCmd.AddCommand(notify.Cmd)
+	rootCmd.AddCommand(config.Cmd)
 }
@@ -0,0 +1,71 @@
+package rotate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/keymanager"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grace time.Duration
+)
+
+var keyCmd = &cobra.Command{
+	Use:   "key <id>",
+	Short: "Rotate an API key",
+	Long: `Mint a successor for an API key and keep the old key valid for a grace period.
+
+The key can be given in full, as a unique prefix, or in the masked form shown by "wsctl get keys".
+The successor inherits the description and scopes of the old key.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRotateKey,
+}
+
+func init() {
+	keyCmd.Flags().DurationVar(&grace, "grace", 24*time.Hour, "How long the old key stays valid (0 revokes it immediately)")
+}
+
+func runRotateKey(cmd *cobra.Command, args []string) error {
+	store, err := common.KeyStore()
+	if err != nil {
+		return err
+	}
+
+	old, err := store.Find(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to find key: %w", err)
+	}
+
+	if old.ReplacedBy != "" {
+		return fmt.Errorf("key %s was already rotated (successor: %s)", auth.MaskKey(old.Key), auth.MaskKey(old.ReplacedBy))
+	}
+
+	newKey, err := keymanager.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	replaced, successor, err := store.Rotate(old.Key, newKey, grace)
+	if err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+
+	fmt.Println("API key rotated successfully")
+	fmt.Printf("Old key: %s\n", auth.MaskKey(old.Key))
+	if replaced.ExpiresAt != nil {
+		fmt.Printf("Old key valid until: %s\n", replaced.ExpiresAt.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Println("Old key revoked")
+	}
+	fmt.Println()
+	fmt.Println(successor.Key)
+	fmt.Println()
+	fmt.Println("Install the new key on clients with:")
+	fmt.Printf("wctl key rotate --key %s\n", successor.Key)
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package rotate
+
+import "github.com/spf13/cobra"
+
+var Cmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate resources",
+	Long:  `Rotate API keys`,
+}
+
+func init() {
+	Cmd.AddCommand(keyCmd)
+}
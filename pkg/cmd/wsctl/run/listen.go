@@ -0,0 +1,135 @@
+package run
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/peercred"
+)
+
+// socketPath returns the path of a unix:///path (or unix:path) address
+func socketPath(address string) (string, bool) {
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return path, true
+	}
+	return strings.CutPrefix(address, "unix:")
+}
+
+// listen opens a TCP listener, or a Unix socket with --socket-mode and --socket-group
+func listen(address string) (net.Listener, error) {
+	path, ok := socketPath(address)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --socket-mode %q: %w", socketMode, err)
+	}
+
+	gid := -1
+	if socketGroup != "" {
+		if gid, err = lookupGID(socketGroup); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	// 소켓은 소유자만 접근 가능한 상태로 만들고, 그룹을 바꾼 뒤에 권한을 넓힘
+	restore := restrictUmask()
+	listener, err := net.Listen("unix", path)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+
+	if gid >= 0 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set socket group: %w", err)
+		}
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes a socket left behind by a server that is no longer running
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", path)
+	}
+
+	return os.Remove(path)
+}
+
+// trustPeers lets processes of --trust-uid users call without an API key
+func trustPeers(isSocket bool) (auth.Option, error) {
+	if !isSocket {
+		return nil, fmt.Errorf("requires a unix:// --listen address")
+	}
+	if !peercred.Supported {
+		return nil, fmt.Errorf("peer credentials are not supported on this platform")
+	}
+
+	uids := make([]int, 0, len(trustUIDs))
+	for _, name := range trustUIDs {
+		uid, err := lookupUID(name)
+		if err != nil {
+			return nil, err
+		}
+		uids = append(uids, uid)
+	}
+
+	slog.Info("trusting local users without API key", "users", strings.Join(trustUIDs, ","))
+	return auth.WithTrustedPeer(peercred.TrustUIDs(uids)), nil
+}
+
+func lookupUID(name string) (int, error) {
+	if uid, err := strconv.Atoi(name); err == nil {
+		return uid, nil
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+func lookupGID(name string) (int, error) {
+	if gid, err := strconv.Atoi(name); err == nil {
+		return gid, nil
+	}
+
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(g.Gid)
+}
@@ -1,98 +1,732 @@
 package run
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/binaryarc/watcher/internal/audit"
 	"github.com/binaryarc/watcher/internal/auth"
+	"github.com/binaryarc/watcher/internal/config"
+	"github.com/binaryarc/watcher/internal/gateway"
 	"github.com/binaryarc/watcher/internal/grpcserver"
-	"github.com/binaryarc/watcher/internal/keystore"
+	"github.com/binaryarc/watcher/internal/history"
+	"github.com/binaryarc/watcher/internal/labels"
+	"github.com/binaryarc/watcher/internal/metrics"
+	"github.com/binaryarc/watcher/internal/notify"
+	"github.com/binaryarc/watcher/internal/peercred"
+	"github.com/binaryarc/watcher/internal/ratelimit"
+	"github.com/binaryarc/watcher/internal/sdnotify"
+	"github.com/binaryarc/watcher/internal/version"
+	"github.com/binaryarc/watcher/pkg/cmd/wsctl/common"
 	"github.com/binaryarc/watcher/proto"
 	"github.com/spf13/cobra"
 	grpcLib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "run",
 	Short: "Start Watcher gRPC server",
-	Long:  `Start the Watcher server to accept remote observation requests`,
-	Run:   runServer,
+	Long: `Start the Watcher server to accept remote observation requests.
+
+SIGTERM or SIGINT stops accepting new calls and waits up to --shutdown-timeout for
+running ones; a second signal exits immediately. Under systemd (Type=notify) readiness,
+shutdown and watchdog pings are reported with sd_notify.
+
+Settings not given as flags are read from the config file (--config, default
+/etc/watcher/wsctl.yaml) and WATCHER_* environment variables; see wsctl config validate.`,
+	RunE: runServer,
 }
 
 var (
-	port            int
-	host            string
-	disableAuth     bool
-	keystorePathArg string
+	port             int
+	host             string
+	disableAuth      bool
+	reloadInterval   time.Duration
+	allowKeyRotation bool
+	rotationGrace    time.Duration
+	labelFlags       []string
+	watchInterval    time.Duration
+	minWatchInterval time.Duration
+	metricsAddr      string
+	httpPort         int
+	listenAddr       string
+	socketMode       string
+	socketGroup      string
+	trustUIDs        []string
+	shutdownTimeout  time.Duration
+	pidFile          string
+	tlsCert          string
+	tlsKey           string
+	tlsClientCA      string
+	cacheTTL         time.Duration
+	cacheRefresh     bool
+	limits           ratelimit.Config
+	maxDetections    int
+	auditFile        string
+	auditMaxSize     int
+	auditMaxBackups  int
+	guardConfig      auth.GuardConfig
+	authMode         string
+	signatureSkew    time.Duration
 )
 
 func init() {
 	Cmd.Flags().IntVarP(&port, "port", "p", 9090, "Port to listen on")
 	Cmd.Flags().StringVar(&host, "host", "0.0.0.0", "Host to bind to")
+	Cmd.Flags().StringVar(&listenAddr, "listen", "", "Address to listen on, host:port or unix:///path/to/socket (overrides --host and --port)")
+	Cmd.Flags().StringVar(&socketMode, "socket-mode", "0660", "File mode of the Unix socket")
+	Cmd.Flags().StringVar(&socketGroup, "socket-group", "", "Group owning the Unix socket (name or GID)")
+	Cmd.Flags().StringSliceVar(&trustUIDs, "trust-uid", []string{}, "Users (names or UIDs) allowed over the Unix socket without an API key, identified by SO_PEERCRED")
+	Cmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Server certificate; enables TLS on the gRPC and HTTP listeners")
+	Cmd.Flags().StringVar(&tlsKey, "tls-key", "", "Server private key")
+	Cmd.Flags().StringVar(&tlsClientCA, "tls-client-ca", "", "Require client certificates signed by this CA (mutual TLS)")
 	Cmd.Flags().BoolVar(&disableAuth, "disable-auth", false, "Disable authentication (use for testing only)")
-	Cmd.Flags().StringVar(&keystorePathArg, "keystore", "", "Path to keystore file (default: ~/.watcher/server/keys.json)")
+	Cmd.Flags().StringVar(&authMode, "auth-mode", config.AuthModeAPIKey, "Accepted credentials: api-key (the key in x-api-key), hmac (requests signed with the key) or any")
+	Cmd.Flags().DurationVar(&signatureSkew, "signature-skew", auth.DefaultSignatureSkew, "How far the timestamp of a signed request may be from the server clock")
+	Cmd.Flags().DurationVar(&reloadInterval, "reload-interval", 2*time.Second, "How often to check the keystore file for changes (0 disables; SIGHUP always reloads)")
+	Cmd.Flags().IntVar(&guardConfig.MaxFailures, "lockout-after", 5, "Lock out clients after this many invalid API keys (0 disables)")
+	Cmd.Flags().DurationVar(&guardConfig.Lockout, "lockout-duration", time.Minute, "First lockout; doubled for every further invalid key")
+	Cmd.Flags().DurationVar(&guardConfig.MaxLockout, "max-lockout", time.Hour, "Longest lockout")
+	Cmd.Flags().StringSliceVar(&guardConfig.Exempt, "lockout-exempt-cidr", []string{}, "Networks never locked out, e.g. 10.0.0.0/8; they still need a valid API key (repeatable)")
+	Cmd.Flags().StringSliceVar(&guardConfig.Deny, "deny-cidr", []string{}, "Networks always rejected (repeatable)")
+	Cmd.Flags().BoolVar(&allowKeyRotation, "allow-key-rotation", false, "Allow clients to rotate their own API key (wctl key rotate)")
+	Cmd.Flags().DurationVar(&rotationGrace, "rotation-grace", 24*time.Hour, "How long a rotated key stays valid after client-initiated rotation")
+	Cmd.Flags().DurationVar(&watchInterval, "watch-interval", grpcserver.DefaultWatchInterval, "How often to re-run detection for watch streams (unless the client asks for an interval) and notifications")
+	Cmd.Flags().DurationVar(&minWatchInterval, "min-watch-interval", grpcserver.DefaultMinWatchInterval, "Shortest detection interval watch clients may ask for")
+	Cmd.Flags().Float64Var(&limits.PerKey.Limit, "key-rate", 0, "Requests per second allowed for each API key (0 means unlimited)")
+	Cmd.Flags().IntVar(&limits.PerKey.Burst, "key-burst", 10, "Requests an API key may make in a burst above --key-rate")
+	Cmd.Flags().Float64Var(&limits.PerPeer.Limit, "peer-rate", 0, "Requests per second allowed for each client address (0 means unlimited)")
+	Cmd.Flags().IntVar(&limits.PerPeer.Burst, "peer-burst", 20, "Requests a client address may make in a burst above --peer-rate")
+	Cmd.Flags().IntVar(&maxDetections, "max-concurrent-detections", 0, "Run at most this many detectors at the same time; further runs wait until their call is cancelled (0 means unlimited)")
+	Cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "Reuse detection results for this long instead of re-running detectors on every call (0 disables)")
+	Cmd.Flags().BoolVar(&cacheRefresh, "cache-refresh", false, "Re-run detection in the background before cached results expire (requires --cache-ttl)")
+	Cmd.Flags().IntVar(&httpPort, "http-port", 0, "Serve the HTTP/JSON API on this port (0 disables; the same as --port shares it with gRPC)")
+	Cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address (e.g., :9100); disabled if empty")
+	Cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 15*time.Second, "How long to wait for running calls (including watch streams) on SIGTERM/SIGINT before closing them")
+	Cmd.Flags().StringVar(&pidFile, "pidfile", "", "Write the process ID to this file while running")
+	Cmd.Flags().StringVar(&auditFile, "audit-log", "", "Record every call as a JSON line in this file (\"-\" for stdout)")
+	Cmd.Flags().IntVar(&auditMaxSize, "audit-max-size", 100, "Rotate the audit log once it reaches this many megabytes (0 disables rotation)")
+	Cmd.Flags().IntVar(&auditMaxBackups, "audit-max-backups", 5, "Rotated audit logs to keep")
+	Cmd.Flags().StringArrayVar(&labelFlags, "label", []string{}, "Label advertised to clients, as key=value (repeatable; overrides labels in the config file)")
 }
 
-func runServer(cmd *cobra.Command, args []string) {
+func runServer(cmd *cobra.Command, args []string) error {
+	// 여기부터의 오류는 사용법 문제가 아님
+	cmd.SilenceUsage = true
+
+	cfg, err := common.Config()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	applyConfig(cmd, cfg)
+
+	closeLog, err := common.SetupLogging(cfg.Logging)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
 	addr := fmt.Sprintf("%s:%d", host, port)
+	if listenAddr != "" {
+		addr = listenAddr
+	}
+	_, isSocket := socketPath(addr)
+
+	httpAddr := cfg.HTTP.Listen
+	if httpAddr == "" || cmd.Flags().Changed("http-port") {
+		httpAddr = httpAddress(addr, isSocket)
+	}
+
+	tlsConfig, err := common.ServerTLS(config.ServerTLS{CertFile: tlsCert, KeyFile: tlsKey, ClientCAFile: tlsClientCA})
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil && isSocket {
+		return fmt.Errorf("TLS is not supported on a Unix socket")
+	}
+
+	detectors, err := common.Detectors()
+	if err != nil {
+		return err
+	}
+	if cacheRefresh && cacheTTL <= 0 {
+		return fmt.Errorf("--cache-refresh requires --cache-ttl")
+	}
+
+	store, err := common.KeyStore()
+	if err != nil {
+		return fmt.Errorf("failed to load keystore: %w", err)
+	}
+
+	serverLabels, err := common.Labels(labelFlags)
+	if err != nil {
+		return fmt.Errorf("failed to parse labels: %w", err)
+	}
+
+	var (
+		unaryInterceptors  []grpcLib.UnaryServerInterceptor
+		streamInterceptors []grpcLib.StreamServerInterceptor
+		authOpts           []auth.Option
+		serverMetrics      *metrics.ServerMetrics
+	)
 
-	keystorePath := keystorePathArg
-	if keystorePath == "" {
-		homeDir, err := os.UserHomeDir()
+	// 감사 로그가 가장 먼저 실행되어야 거부된 호출도 기록됨
+	if auditFile != "" {
+		auditLog, closeAudit, err := openAuditLog()
 		if err != nil {
-			fmt.Printf("Failed to get home directory: %v\n", err)
-			return
+			return err
 		}
-		keysDir := filepath.Join(homeDir, ".watcher", "server")
-		if err := os.MkdirAll(keysDir, 0700); err != nil {
-			fmt.Printf("Failed to create keys directory: %v\n", err)
-			return
-		}
-		keystorePath = filepath.Join(keysDir, "keys.json")
+		defer closeAudit()
+
+		unaryInterceptors = append(unaryInterceptors, auditLog.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, auditLog.StreamServerInterceptor())
+		authOpts = append(authOpts, auth.WithFailureHook(auditLog.AuthFailure))
+		slog.Info("audit logging enabled", "file", auditFile)
 	}
 
-	store, err := keystore.NewStore(keystorePath)
-	if err != nil {
-		fmt.Printf("Failed to load keystore: %v\n", err)
-		return
+	// metrics 인터셉터가 먼저 실행되어야 인증 실패도 코드별로 집계됨
+	if metricsAddr != "" {
+		serverMetrics = metrics.NewServerMetrics()
+		unaryInterceptors = append(unaryInterceptors, serverMetrics.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, serverMetrics.StreamServerInterceptor())
+		authOpts = append(authOpts, auth.WithFailureHook(serverMetrics.AuthFailure))
 	}
 
-	var grpcServer *grpcLib.Server
 	if disableAuth {
-		fmt.Println("WARNING: Authentication DISABLED - not recommended for production")
-		grpcServer = grpcLib.NewServer()
+		slog.Warn("authentication disabled - not recommended for production")
+		if len(guardConfig.Deny) > 0 {
+			return fmt.Errorf("--deny-cidr requires authentication")
+		}
+		if authMode != config.AuthModeAPIKey {
+			return fmt.Errorf("--auth-mode %s requires authentication", authMode)
+		}
 	} else {
 		if store.IsEmpty() {
-			fmt.Println("WARNING: No API keys registered - all requests will be rejected")
-			fmt.Println("Add keys with: watcher-server key add <api-key> \"<description>\"")
+			slog.Warn("no API keys registered - all requests will be rejected (add keys with: wsctl add key)")
 		} else {
-			keyCount := len(store.List())
-			fmt.Printf("Authentication enabled (%d key(s) registered)\n", keyCount)
+			slog.Info("authentication enabled", "keys", len(store.List()))
+		}
+
+		guard, err := auth.NewGuard(guardConfig)
+		if err != nil {
+			return err
+		}
+		guard.OnLockout(func(client string, failures int, lockout time.Duration) {
+			slog.Warn("client locked out after invalid API keys", "client", client, "failures", failures, "lockout", lockout)
+		})
+		if serverMetrics != nil {
+			guard.OnLockout(serverMetrics.Lockout)
+		}
+		authOpts = append(authOpts, auth.WithGuard(guard))
+
+		signatures, err := signatureOption(store)
+		if err != nil {
+			return err
+		}
+		if signatures != nil {
+			authOpts = append(authOpts, signatures)
+			slog.Info("signed requests enabled", "mode", authMode, "skew", signatureSkew)
+		}
+
+		if len(trustUIDs) > 0 {
+			trustOpt, err := trustPeers(isSocket)
+			if err != nil {
+				return fmt.Errorf("invalid --trust-uid: %w", err)
+			}
+			authOpts = append(authOpts, trustOpt)
 		}
 
-		grpcServer = grpcLib.NewServer(
-			grpcLib.UnaryInterceptor(auth.UnaryServerInterceptor(store)),
-			grpcLib.StreamInterceptor(auth.StreamServerInterceptor(store)),
-		)
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(store, authOpts...))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(store, authOpts...))
+
+		common.WatchKeyStore(store, reloadInterval)
 	}
 
-	listener, err := net.Listen("tcp", addr)
+	// 인증 뒤에 두어 유효한 키만 버킷을 가짐
+	if limits.Enabled() {
+		limiter := ratelimit.New(limits)
+		if serverMetrics != nil {
+			limiter.OnReject(serverMetrics.RateLimited)
+		}
+		unaryInterceptors = append(unaryInterceptors, limiter.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, limiter.StreamServerInterceptor())
+		slog.Info("rate limits enabled",
+			"key_rate", limits.PerKey.Limit, "peer_rate", limits.PerPeer.Limit)
+		if disableAuth && limits.PerKey.Enabled() {
+			slog.Warn("per-key rate limit has no effect without authentication - use --peer-rate")
+		}
+	}
+
+	sharedPort := httpAddr == addr && !isSocket
+
+	grpcOpts := []grpcLib.ServerOption{
+		grpcLib.ChainUnaryInterceptor(unaryInterceptors...),
+		grpcLib.ChainStreamInterceptor(streamInterceptors...),
+	}
+	switch {
+	case isSocket:
+		// 유닉스 소켓에서는 접속한 프로세스의 UID를 기록 (--trust-uid)
+		grpcOpts = append(grpcOpts, grpcLib.Creds(peercred.NewCredentials()))
+	case tlsConfig != nil && !sharedPort:
+		// 포트를 공유하면 http.Server가 TLS를 처리
+		grpcOpts = append(grpcOpts, grpcLib.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpcLib.NewServer(grpcOpts...)
+
+	listener, err := listen(addr)
 	if err != nil {
-		fmt.Printf("Failed to listen on %s: %v\n", addr, err)
-		return
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	serverOpts := []grpcserver.Option{
+		grpcserver.WithWatchInterval(watchInterval),
+		grpcserver.WithMinWatchInterval(minWatchInterval),
+		grpcserver.WithDetectors(detectors),
+		grpcserver.WithCacheTTL(cacheTTL),
+		grpcserver.WithMaxConcurrentDetections(maxDetections),
+	}
+	if cacheTTL > 0 {
+		slog.Info("detection cache enabled", "ttl", cacheTTL, "refresh", cacheRefresh)
+	}
+	if disableAuth {
+		serverOpts = append(serverOpts, grpcserver.WithAuthMode(grpcserver.AuthModeDisabled))
+	} else {
+		serverOpts = append(serverOpts, grpcserver.WithAuthMode(reportedAuthMode()))
+	}
+	if allowKeyRotation && !disableAuth {
+		serverOpts = append(serverOpts, grpcserver.WithKeyRotation(store, rotationGrace))
+		slog.Info("client key rotation enabled", "grace", rotationGrace)
+	}
+
+	if len(serverLabels) > 0 {
+		serverOpts = append(serverOpts, grpcserver.WithLabels(serverLabels))
+		slog.Info("server labels", "labels", labels.Format(serverLabels))
+	}
+
+	if serverMetrics != nil {
+		serverOpts = append(serverOpts, grpcserver.WithDetectionObserver(serverMetrics))
+	}
+
+	watcherServer := grpcserver.NewWatcherServer(serverOpts...)
+
+	dispatcher, err := common.Notifications()
+	if err != nil {
+		return fmt.Errorf("failed to configure notifications: %w", err)
+	}
+	if dispatcher != nil {
+		slog.Info("notifications enabled", "notifiers", strings.Join(dispatcher.Names(), ","))
+	}
+
+	// SIGTERM/SIGINT: 새 요청을 받지 않고 진행 중인 요청을 기다린 뒤 종료
+	ctx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	// 모든 리스너를 READY 알림 전에 열어서 바인드 실패 시 시작이 실패하도록 함
+	serveErr := make(chan error, 3)
+
+	var httpServers []*http.Server
+	if serverMetrics != nil {
+		metricsServer, err := serveMetrics(serverMetrics, serveErr)
+		if err != nil {
+			return err
+		}
+		httpServers = append(httpServers, metricsServer)
+	}
+
+	if cacheRefresh {
+		go watcherServer.RefreshCache(ctx)
+	}
+
+	// 알림과 메트릭 모두 주기적인 감지 결과가 필요
+	if dispatcher != nil || serverMetrics != nil {
+		go watchHost(ctx, watcherServer, dispatcher, serverLabels)
 	}
 
-	watcherServer := grpcserver.NewWatcherServer()
 	proto.RegisterWatcherServiceServer(grpcServer, watcherServer)
+	healthServer := common.RegisterHealth(grpcServer, proto.WatcherService_ServiceDesc.ServiceName)
 	reflection.Register(grpcServer)
 
-	fmt.Printf("Watcher server listening on %s...\n", addr)
-	fmt.Println("Press Ctrl+C to stop")
+	slog.Info("watcher server listening", "version", version.String(), "address", addr, "tls", tlsConfig != nil)
+
+	var serve func() error
+	switch {
+	case httpAddr == "":
+		serve = func() error { return grpcServer.Serve(listener) }
+	case sharedPort:
+		// HTTP와 gRPC가 같은 포트를 공유 (TLS가 없으면 gRPC는 평문 HTTP/2로 들어옴)
+		shared := gateway.NewServer(gateway.WithGRPC(grpcServer, gateway.New(watcherServer, unaryInterceptors...)))
+		shared.TLSConfig = tlsConfig
+		httpServers = append(httpServers, shared)
+		serve = func() error {
+			if tlsConfig != nil {
+				return shared.ServeTLS(listener, "", "")
+			}
+			return shared.Serve(listener)
+		}
+		slog.Info("HTTP API available", "url", apiURL(addr, tlsConfig != nil))
+	default:
+		gatewayServer, err := serveGateway(gateway.New(watcherServer, unaryInterceptors...), httpAddr, tlsConfig, serveErr)
+		if err != nil {
+			return err
+		}
+		httpServers = append(httpServers, gatewayServer)
+		serve = func() error { return grpcServer.Serve(listener) }
+	}
+
+	if pidFile != "" {
+		removePIDFile, err := common.WritePIDFile(pidFile)
+		if err != nil {
+			return err
+		}
+		defer removePIDFile()
+	}
+
+	go func() { serveErr <- serve() }()
 
-	if err := grpcServer.Serve(listener); err != nil {
-		fmt.Printf("Failed to serve: %v\n", err)
+	notifySystemd(sdnotify.Ready)
+	go watchdog(ctx)
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			return fmt.Errorf("failed to serve: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
 	}
+
+	// 두 번째 신호는 기본 동작대로 즉시 종료
+	stopSignals()
+	slog.Info("shutting down, waiting for running calls", "timeout", shutdownTimeout)
+	notifySystemd(sdnotify.Stopping)
+	healthServer.Shutdown()
+
+	if !drain(grpcServer, !sharedPort, httpServers) {
+		slog.Warn("shutdown timeout reached, closed remaining connections")
+	}
+	slog.Info("server stopped")
+
+	return nil
+}
+
+// applyConfig takes the settings of the config file for flags not given on the command line
+func applyConfig(cmd *cobra.Command, cfg *config.ServerConfig) {
+	// 파일이나 환경 변수에 있고 플래그로 주지 않은 설정만 적용 (0이나 빈 값도 그대로 적용)
+	apply := func(key string, flags ...string) bool {
+		if !cfg.IsSet(key) {
+			return false
+		}
+		for _, name := range flags {
+			if cmd.Flags().Changed(name) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if apply("listen", "listen", "host", "port") {
+		listenAddr = cfg.Listen
+	}
+	if apply("socket.mode", "socket-mode") {
+		socketMode = cfg.Socket.Mode
+	}
+	if apply("socket.group", "socket-group") {
+		socketGroup = cfg.Socket.Group
+	}
+	if apply("socket.trust-uids", "trust-uid") {
+		trustUIDs = cfg.Socket.TrustUIDs
+	}
+	if apply("tls.cert-file", "tls-cert", "tls-key") || apply("tls.key-file", "tls-cert", "tls-key") {
+		tlsCert, tlsKey = cfg.TLS.CertFile, cfg.TLS.KeyFile
+	}
+	if apply("tls.client-ca-file", "tls-client-ca") {
+		tlsClientCA = cfg.TLS.ClientCAFile
+	}
+
+	if apply("auth.disabled", "disable-auth") {
+		disableAuth = cfg.Auth.Disabled
+	}
+	if apply("auth.mode", "auth-mode") {
+		authMode = cfg.Auth.Mode
+	}
+	if apply("auth.signature-skew", "signature-skew") {
+		signatureSkew = cfg.Auth.SignatureSkew
+	}
+	if apply("auth.reload-interval", "reload-interval") {
+		reloadInterval = cfg.Auth.ReloadInterval
+	}
+	if apply("auth.allow-key-rotation", "allow-key-rotation") {
+		allowKeyRotation = cfg.Auth.AllowKeyRotation
+	}
+	if apply("auth.rotation-grace", "rotation-grace") {
+		rotationGrace = cfg.Auth.RotationGrace
+	}
+
+	if apply("auth.lockout-after", "lockout-after") {
+		guardConfig.MaxFailures = cfg.Auth.LockoutAfter
+	}
+	if apply("auth.lockout-duration", "lockout-duration") {
+		guardConfig.Lockout = cfg.Auth.LockoutDuration
+	}
+	if apply("auth.max-lockout", "max-lockout") {
+		guardConfig.MaxLockout = cfg.Auth.MaxLockout
+	}
+	if apply("auth.lockout-exempt-cidrs", "lockout-exempt-cidr") {
+		guardConfig.Exempt = cfg.Auth.LockoutExemptCIDRs
+	}
+	if apply("auth.deny-cidrs", "deny-cidr") {
+		guardConfig.Deny = cfg.Auth.DenyCIDRs
+	}
+
+	if apply("limits.key-rate", "key-rate") {
+		limits.PerKey.Limit = cfg.Limits.KeyRate
+	}
+	if apply("limits.key-burst", "key-burst") {
+		limits.PerKey.Burst = cfg.Limits.KeyBurst
+	}
+	if apply("limits.peer-rate", "peer-rate") {
+		limits.PerPeer.Limit = cfg.Limits.PeerRate
+	}
+	if apply("limits.peer-burst", "peer-burst") {
+		limits.PerPeer.Burst = cfg.Limits.PeerBurst
+	}
+	if apply("limits.max-concurrent-detections", "max-concurrent-detections") {
+		maxDetections = cfg.Limits.MaxConcurrentDetections
+	}
+
+	if apply("audit.file", "audit-log") {
+		auditFile = cfg.Audit.File
+	}
+	if apply("audit.max-size-mb", "audit-max-size") {
+		auditMaxSize = cfg.Audit.MaxSizeMB
+	}
+	if apply("audit.max-backups", "audit-max-backups") {
+		auditMaxBackups = cfg.Audit.MaxBackups
+	}
+
+	if apply("cache.ttl", "cache-ttl") {
+		cacheTTL = cfg.Cache.TTL
+	}
+	if apply("cache.refresh", "cache-refresh") {
+		cacheRefresh = cfg.Cache.Refresh
+	}
+
+	if apply("watch-interval", "watch-interval") {
+		watchInterval = cfg.WatchInterval
+	}
+	if apply("min-watch-interval", "min-watch-interval") {
+		minWatchInterval = cfg.MinWatchInterval
+	}
+	if apply("metrics.listen", "metrics-addr") {
+		metricsAddr = cfg.Metrics.Listen
+	}
+	if apply("shutdown-timeout", "shutdown-timeout") {
+		shutdownTimeout = cfg.ShutdownTimeout
+	}
+	if apply("pidfile", "pidfile") {
+		pidFile = cfg.PIDFile
+	}
+}
+
+// signatureOption returns the auth option for --auth-mode, or nil if only plain API keys are accepted
+func signatureOption(keys auth.KeyResolver) (auth.Option, error) {
+	switch authMode {
+	case config.AuthModeAPIKey:
+		return nil, nil
+	case config.AuthModeHMAC:
+		return auth.WithSignatures(auth.NewSignatureVerifier(keys, signatureSkew), true), nil
+	case config.AuthModeAny:
+		return auth.WithSignatures(auth.NewSignatureVerifier(keys, signatureSkew), false), nil
+	}
+	return nil, fmt.Errorf("invalid --auth-mode %q (expected %s, %s or %s)", authMode, config.AuthModeAPIKey, config.AuthModeHMAC, config.AuthModeAny)
+}
+
+// reportedAuthMode is the --auth-mode as reported by GetServerInfo
+func reportedAuthMode() string {
+	switch authMode {
+	case config.AuthModeHMAC:
+		return grpcserver.AuthModeHMAC
+	case config.AuthModeAny:
+		return grpcserver.AuthModeAPIKeyOrHMAC
+	}
+	return grpcserver.AuthModeAPIKey
+}
+
+// openAuditLog opens --audit-log, rotated by size unless it is stdout
+func openAuditLog() (*audit.Logger, func(), error) {
+	if auditFile == "-" {
+		return audit.New(os.Stdout), func() {}, nil
+	}
+
+	file, err := audit.OpenRotatingFile(auditFile, int64(auditMaxSize)<<20, auditMaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	file.OnError(func(err error) {
+		slog.Error("audit log write failed", "path", auditFile, "error", err)
+	})
+	return audit.New(file), func() { file.Close() }, nil
+}
+
+// httpAddress returns where --http-port serves the HTTP API: the host of the gRPC
+// listener, or --host when that is a Unix socket
+func httpAddress(addr string, isSocket bool) string {
+	if httpPort == 0 {
+		return ""
+	}
+
+	httpHost := host
+	if listenHost, _, err := net.SplitHostPort(addr); err == nil && !isSocket {
+		httpHost = listenHost
+	}
+	return net.JoinHostPort(httpHost, strconv.Itoa(httpPort))
+}
+
+func apiURL(addr string, secure bool) string {
+	if secure {
+		return "https://" + addr + "/v1/"
+	}
+	return "http://" + addr + "/v1/"
+}
+
+// drain stops accepting connections and waits up to --shutdown-timeout for running calls,
+// then closes whatever is left (watch streams only end when the client goes away).
+// GracefulStop can't be used when gRPC is served through an http.Server.
+func drain(grpcServer *grpcLib.Server, graceful bool, httpServers []*http.Server) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for _, server := range httpServers {
+			wg.Add(1)
+			go func(server *http.Server) {
+				defer wg.Done()
+				server.Shutdown(ctx)
+			}(server)
+		}
+		if graceful {
+			grpcServer.GracefulStop()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+		grpcServer.Stop()
+		return true
+	case <-ctx.Done():
+		for _, server := range httpServers {
+			server.Close()
+		}
+		grpcServer.Stop()
+		return false
+	}
+}
+
+// notifySystemd reports state to systemd when running as a Type=notify service
+func notifySystemd(state string) {
+	if _, err := sdnotify.Notify(state); err != nil {
+		slog.Warn("failed to notify systemd", "error", err)
+	}
+}
+
+// watchdog pings the systemd watchdog at half the interval it expects (WatchdogSec=)
+func watchdog(ctx context.Context) {
+	interval, ok := sdnotify.WatchdogInterval()
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notifySystemd(sdnotify.Watchdog)
+		}
+	}
+}
+
+// serveGateway exposes the HTTP/JSON API on its own port, with TLS if configured
+func serveGateway(handler http.Handler, addr string, tlsConfig *tls.Config, errs chan<- error) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on HTTP address %s: %w", addr, err)
+	}
+	slog.Info("HTTP API available", "url", apiURL(addr, tlsConfig != nil))
+
+	server := gateway.NewServer(handler)
+	server.TLSConfig = tlsConfig
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != http.ErrServerClosed {
+			errs <- fmt.Errorf("failed to serve HTTP API: %w", err)
+		}
+	}()
+	return server, nil
+}
+
+// watchHost re-runs detection every --watch-interval, keeping metrics current and
+// sending runtime_changed notifications for this host
+func watchHost(ctx context.Context, server *grpcserver.WatcherServer, dispatcher *notify.Dispatcher, serverLabels map[string]string) {
+	hostname, _ := os.Hostname()
+
+	err := server.Watch(ctx, nil, watchInterval, false, func(changes []history.Change) error {
+		if dispatcher != nil {
+			dispatcher.Dispatch(notify.RuntimeEvents(hostname, serverLabels, changes)...)
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		slog.Error("stopped watching runtimes", "error", err)
+	}
+}
+
+// serveMetrics exposes Prometheus metrics on --metrics-addr
+func serveMetrics(serverMetrics *metrics.ServerMetrics, errs chan<- error) (*http.Server, error) {
+	listener, err := net.Listen("tcp", metricsAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on metrics address %s: %w", metricsAddr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", serverMetrics.Registry.Handler())
+
+	slog.Info("metrics available", "url", "http://"+metricsAddr+"/metrics")
+
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			errs <- fmt.Errorf("failed to serve metrics: %w", err)
+		}
+	}()
+	return server, nil
 }
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package run
+
+// restrictUmask is a no-op where there is no umask
+func restrictUmask() (restore func()) {
+	return func() {}
+}
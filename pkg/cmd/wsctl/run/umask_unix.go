@@ -0,0 +1,14 @@
+//go:build unix
+
+package run
+
+import "syscall"
+
+// restrictUmask makes files created until the returned function is called accessible
+// only to the owner. The umask is per process, so it must only be held briefly.
+func restrictUmask() (restore func()) {
+	old := syscall.Umask(0177)
+	return func() {
+		syscall.Umask(old)
+	}
+}
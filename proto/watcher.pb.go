@@ -21,6 +21,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type RuntimeEvent_Type int32
+
+const (
+	RuntimeEvent_TYPE_UNSPECIFIED RuntimeEvent_Type = 0
+	RuntimeEvent_ADDED            RuntimeEvent_Type = 1
+	RuntimeEvent_REMOVED          RuntimeEvent_Type = 2
+	RuntimeEvent_CHANGED          RuntimeEvent_Type = 3
+)
+
+// Enum value maps for RuntimeEvent_Type.
+var (
+	RuntimeEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "ADDED",
+		2: "REMOVED",
+		3: "CHANGED",
+	}
+	RuntimeEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"ADDED":            1,
+		"REMOVED":          2,
+		"CHANGED":          3,
+	}
+)
+
+func (x RuntimeEvent_Type) Enum() *RuntimeEvent_Type {
+	p := new(RuntimeEvent_Type)
+	*p = x
+	return p
+}
+
+func (x RuntimeEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RuntimeEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_watcher_proto_enumTypes[0].Descriptor()
+}
+
+func (RuntimeEvent_Type) Type() protoreflect.EnumType {
+	return &file_proto_watcher_proto_enumTypes[0]
+}
+
+func (x RuntimeEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RuntimeEvent_Type.Descriptor instead.
+func (RuntimeEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{7, 0}
+}
+
 type Runtime struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -94,6 +146,7 @@ type SystemInfo struct {
 	Hostname      string                 `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
 	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
 	Kernel        string                 `protobuf:"bytes,3,opt,name=kernel,proto3" json:"kernel,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -149,9 +202,18 @@ func (x *SystemInfo) GetKernel() string {
 	return ""
 }
 
+func (x *SystemInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 type ObserveRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RuntimeFilter []string               `protobuf:"bytes,1,rep,name=runtime_filter,json=runtimeFilter,proto3" json:"runtime_filter,omitempty"`
+	// re-run detection even if the server has cached results
+	ForceRefresh  bool `protobuf:"varint,2,opt,name=force_refresh,json=forceRefresh,proto3" json:"force_refresh,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -193,13 +255,23 @@ func (x *ObserveRequest) GetRuntimeFilter() []string {
 	return nil
 }
 
+func (x *ObserveRequest) GetForceRefresh() bool {
+	if x != nil {
+		return x.ForceRefresh
+	}
+	return false
+}
+
 type ObserveResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Runtimes      []*Runtime             `protobuf:"bytes,1,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
-	SystemInfo    *SystemInfo            `protobuf:"bytes,2,opt,name=system_info,json=systemInfo,proto3" json:"system_info,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Runtimes   []*Runtime             `protobuf:"bytes,1,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
+	SystemInfo *SystemInfo            `protobuf:"bytes,2,opt,name=system_info,json=systemInfo,proto3" json:"system_info,omitempty"`
+	// when the runtimes were detected (the oldest cached result)
+	Timestamp int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// how old the oldest cached result is; 0 if detection just ran
+	CacheAgeSeconds int64 `protobuf:"varint,4,opt,name=cache_age_seconds,json=cacheAgeSeconds,proto3" json:"cache_age_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ObserveResponse) Reset() {
@@ -253,30 +325,922 @@ func (x *ObserveResponse) GetTimestamp() int64 {
 	return 0
 }
 
-var File_proto_watcher_proto protoreflect.FileDescriptor
+func (x *ObserveResponse) GetCacheAgeSeconds() int64 {
+	if x != nil {
+		return x.CacheAgeSeconds
+	}
+	return 0
+}
 
-const file_proto_watcher_proto_rawDesc = "" +
-	"\n" +
-	"\x13proto/watcher.proto\x12\awatcher\"a\n" +
-	"\aRuntime\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
-	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
-	"\x04path\x18\x03 \x01(\tR\x04path\x12\x14\n" +
-	"\x05found\x18\x04 \x01(\bR\x05found\"P\n" +
-	"\n" +
-	"SystemInfo\x12\x1a\n" +
-	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
-	"\x02os\x18\x02 \x01(\tR\x02os\x12\x16\n" +
-	"\x06kernel\x18\x03 \x01(\tR\x06kernel\"7\n" +
-	"\x0eObserveRequest\x12%\n" +
-	"\x0eruntime_filter\x18\x01 \x03(\tR\rruntimeFilter\"\x93\x01\n" +
-	"\x0fObserveResponse\x12,\n" +
-	"\bruntimes\x18\x01 \x03(\v2\x10.watcher.RuntimeR\bruntimes\x124\n" +
-	"\vsystem_info\x18\x02 \x01(\v2\x13.watcher.SystemInfoR\n" +
-	"systemInfo\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp2V\n" +
-	"\x0eWatcherService\x12D\n" +
-	"\x0fObserveRuntimes\x12\x17.watcher.ObserveRequest\x1a\x18.watcher.ObserveResponseB$Z\"github.com/binaryarc/watcher/protob\x06proto3"
+type RotateKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateKeyRequest) Reset() {
+	*x = RotateKeyRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateKeyRequest) ProtoMessage() {}
+
+func (x *RotateKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateKeyRequest.ProtoReflect.Descriptor instead.
+func (*RotateKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{4}
+}
+
+type RotateKeyResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	NewKey          string                 `protobuf:"bytes,1,opt,name=new_key,json=newKey,proto3" json:"new_key,omitempty"`
+	OldKeyExpiresAt int64                  `protobuf:"varint,2,opt,name=old_key_expires_at,json=oldKeyExpiresAt,proto3" json:"old_key_expires_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RotateKeyResponse) Reset() {
+	*x = RotateKeyResponse{}
+	mi := &file_proto_watcher_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateKeyResponse) ProtoMessage() {}
+
+func (x *RotateKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateKeyResponse.ProtoReflect.Descriptor instead.
+func (*RotateKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RotateKeyResponse) GetNewKey() string {
+	if x != nil {
+		return x.NewKey
+	}
+	return ""
+}
+
+func (x *RotateKeyResponse) GetOldKeyExpiresAt() int64 {
+	if x != nil {
+		return x.OldKeyExpiresAt
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuntimeFilter []string               `protobuf:"bytes,1,rep,name=runtime_filter,json=runtimeFilter,proto3" json:"runtime_filter,omitempty"`
+	// how often to re-run detection; the server default if 0
+	IntervalSeconds int32 `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	// send an ADDED event for every runtime found at the start
+	IncludeInitial bool `protobuf:"varint,3,opt,name=include_initial,json=includeInitial,proto3" json:"include_initial,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchRequest) GetRuntimeFilter() []string {
+	if x != nil {
+		return x.RuntimeFilter
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetIntervalSeconds() int32 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *WatchRequest) GetIncludeInitial() bool {
+	if x != nil {
+		return x.IncludeInitial
+	}
+	return false
+}
+
+type RuntimeEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  RuntimeEvent_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=watcher.RuntimeEvent_Type" json:"type,omitempty"`
+	// the runtime after the change; for REMOVED the last known state
+	Runtime *Runtime `protobuf:"bytes,2,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// empty for ADDED
+	PreviousVersion string `protobuf:"bytes,3,opt,name=previous_version,json=previousVersion,proto3" json:"previous_version,omitempty"`
+	Timestamp       int64  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RuntimeEvent) Reset() {
+	*x = RuntimeEvent{}
+	mi := &file_proto_watcher_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuntimeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuntimeEvent) ProtoMessage() {}
+
+func (x *RuntimeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuntimeEvent.ProtoReflect.Descriptor instead.
+func (*RuntimeEvent) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RuntimeEvent) GetType() RuntimeEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return RuntimeEvent_TYPE_UNSPECIFIED
+}
+
+func (x *RuntimeEvent) GetRuntime() *Runtime {
+	if x != nil {
+		return x.Runtime
+	}
+	return nil
+}
+
+func (x *RuntimeEvent) GetPreviousVersion() string {
+	if x != nil {
+		return x.PreviousVersion
+	}
+	return ""
+}
+
+func (x *RuntimeEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type ServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfoRequest) Reset() {
+	*x = ServerInfoRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfoRequest) ProtoMessage() {}
+
+func (x *ServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*ServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{8}
+}
+
+type ServerInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// watcher build version, e.g. "v1.4.0"
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// version of this proto API, e.g. "v1"
+	ApiVersion string `protobuf:"bytes,2,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	// runtimes this server can detect
+	Detectors     []string          `protobuf:"bytes,3,rep,name=detectors,proto3" json:"detectors,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	UptimeSeconds int64             `protobuf:"varint,5,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// "api_key" or "disabled"
+	AuthMode      string `protobuf:"bytes,6,opt,name=auth_mode,json=authMode,proto3" json:"auth_mode,omitempty"`
+	Hostname      string `protobuf:"bytes,7,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfoResponse) Reset() {
+	*x = ServerInfoResponse{}
+	mi := &file_proto_watcher_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfoResponse) ProtoMessage() {}
+
+func (x *ServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*ServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ServerInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetApiVersion() string {
+	if x != nil {
+		return x.ApiVersion
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetDetectors() []string {
+	if x != nil {
+		return x.Detectors
+	}
+	return nil
+}
+
+func (x *ServerInfoResponse) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ServerInfoResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *ServerInfoResponse) GetAuthMode() string {
+	if x != nil {
+		return x.AuthMode
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+// HostReport is the latest observation a collector holds for one agent
+type HostReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Observation   *ObserveResponse       `protobuf:"bytes,3,opt,name=observation,proto3" json:"observation,omitempty"`
+	LastSeen      int64                  `protobuf:"varint,4,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostReport) Reset() {
+	*x = HostReport{}
+	mi := &file_proto_watcher_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostReport) ProtoMessage() {}
+
+func (x *HostReport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostReport.ProtoReflect.Descriptor instead.
+func (*HostReport) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HostReport) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HostReport) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *HostReport) GetObservation() *ObserveResponse {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+func (x *HostReport) GetLastSeen() int64 {
+	if x != nil {
+		return x.LastSeen
+	}
+	return 0
+}
+
+func (x *HostReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type QueryFleetRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// agent names or addresses; empty means every known agent
+	Hosts []string `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	// label selector, e.g. "env=prod,role!=db"
+	Selector      string `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryFleetRequest) Reset() {
+	*x = QueryFleetRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryFleetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryFleetRequest) ProtoMessage() {}
+
+func (x *QueryFleetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryFleetRequest.ProtoReflect.Descriptor instead.
+func (*QueryFleetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *QueryFleetRequest) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *QueryFleetRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+type QueryFleetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hosts         []*HostReport          `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryFleetResponse) Reset() {
+	*x = QueryFleetResponse{}
+	mi := &file_proto_watcher_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryFleetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryFleetResponse) ProtoMessage() {}
+
+func (x *QueryFleetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryFleetResponse.ProtoReflect.Descriptor instead.
+func (*QueryFleetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *QueryFleetResponse) GetHosts() []*HostReport {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+type PushRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// agent name; defaults to the hostname in the observation
+	Host          string           `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Observation   *ObserveResponse `protobuf:"bytes,2,opt,name=observation,proto3" json:"observation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushRequest) Reset() {
+	*x = PushRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushRequest) ProtoMessage() {}
+
+func (x *PushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushRequest.ProtoReflect.Descriptor instead.
+func (*PushRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PushRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *PushRequest) GetObservation() *ObserveResponse {
+	if x != nil {
+		return x.Observation
+	}
+	return nil
+}
+
+type PushResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accepted      int32                  `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushResponse) Reset() {
+	*x = PushResponse{}
+	mi := &file_proto_watcher_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushResponse) ProtoMessage() {}
+
+func (x *PushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushResponse.ProtoReflect.Descriptor instead.
+func (*PushResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PushResponse) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+type HistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// agent name or address
+	Host string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	// only changes of this runtime; empty means all runtimes
+	Runtime       string `protobuf:"bytes,2,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRequest) Reset() {
+	*x = HistoryRequest{}
+	mi := &file_proto_watcher_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRequest) ProtoMessage() {}
+
+func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRequest.ProtoReflect.Descriptor instead.
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *HistoryRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HistoryRequest) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+// RuntimeChange is a runtime that appeared, disappeared or changed version
+type RuntimeChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ObserveResponse.timestamp of the observation that showed the change
+	Timestamp int64  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Runtime   string `protobuf:"bytes,2,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// empty if the runtime appeared
+	PreviousVersion string `protobuf:"bytes,3,opt,name=previous_version,json=previousVersion,proto3" json:"previous_version,omitempty"`
+	// empty if the runtime disappeared
+	Version       string `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Path          string `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuntimeChange) Reset() {
+	*x = RuntimeChange{}
+	mi := &file_proto_watcher_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuntimeChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuntimeChange) ProtoMessage() {}
+
+func (x *RuntimeChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuntimeChange.ProtoReflect.Descriptor instead.
+func (*RuntimeChange) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RuntimeChange) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *RuntimeChange) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *RuntimeChange) GetPreviousVersion() string {
+	if x != nil {
+		return x.PreviousVersion
+	}
+	return ""
+}
+
+func (x *RuntimeChange) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *RuntimeChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type HistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Host          string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Changes       []*RuntimeChange       `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryResponse) Reset() {
+	*x = HistoryResponse{}
+	mi := &file_proto_watcher_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResponse) ProtoMessage() {}
+
+func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_watcher_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResponse.ProtoReflect.Descriptor instead.
+func (*HistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_watcher_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *HistoryResponse) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HistoryResponse) GetChanges() []*RuntimeChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+var File_proto_watcher_proto protoreflect.FileDescriptor
+
+const file_proto_watcher_proto_rawDesc = "" +
+	"\n" +
+	"\x13proto/watcher.proto\x12\awatcher\"a\n" +
+	"\aRuntime\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x14\n" +
+	"\x05found\x18\x04 \x01(\bR\x05found\"\xc4\x01\n" +
+	"\n" +
+	"SystemInfo\x12\x1a\n" +
+	"\bhostname\x18\x01 \x01(\tR\bhostname\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x16\n" +
+	"\x06kernel\x18\x03 \x01(\tR\x06kernel\x127\n" +
+	"\x06labels\x18\x04 \x03(\v2\x1f.watcher.SystemInfo.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\x0eObserveRequest\x12%\n" +
+	"\x0eruntime_filter\x18\x01 \x03(\tR\rruntimeFilter\x12#\n" +
+	"\rforce_refresh\x18\x02 \x01(\bR\fforceRefresh\"\xbf\x01\n" +
+	"\x0fObserveResponse\x12,\n" +
+	"\bruntimes\x18\x01 \x03(\v2\x10.watcher.RuntimeR\bruntimes\x124\n" +
+	"\vsystem_info\x18\x02 \x01(\v2\x13.watcher.SystemInfoR\n" +
+	"systemInfo\x12\x1c\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12*\n" +
+	"\x11cache_age_seconds\x18\x04 \x01(\x03R\x0fcacheAgeSeconds\"\x12\n" +
+	"\x10RotateKeyRequest\"Y\n" +
+	"\x11RotateKeyResponse\x12\x17\n" +
+	"\anew_key\x18\x01 \x01(\tR\x06newKey\x12+\n" +
+	"\x12old_key_expires_at\x18\x02 \x01(\x03R\x0foldKeyExpiresAt\"\x89\x01\n" +
+	"\fWatchRequest\x12%\n" +
+	"\x0eruntime_filter\x18\x01 \x03(\tR\rruntimeFilter\x12)\n" +
+	"\x10interval_seconds\x18\x02 \x01(\x05R\x0fintervalSeconds\x12'\n" +
+	"\x0finclude_initial\x18\x03 \x01(\bR\x0eincludeInitial\"\xf6\x01\n" +
+	"\fRuntimeEvent\x12.\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1a.watcher.RuntimeEvent.TypeR\x04type\x12*\n" +
+	"\aruntime\x18\x02 \x01(\v2\x10.watcher.RuntimeR\aruntime\x12)\n" +
+	"\x10previous_version\x18\x03 \x01(\tR\x0fpreviousVersion\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\x03R\ttimestamp\"A\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\t\n" +
+	"\x05ADDED\x10\x01\x12\v\n" +
+	"\aREMOVED\x10\x02\x12\v\n" +
+	"\aCHANGED\x10\x03\"\x13\n" +
+	"\x11ServerInfoRequest\"\xc9\x02\n" +
+	"\x12ServerInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1f\n" +
+	"\vapi_version\x18\x02 \x01(\tR\n" +
+	"apiVersion\x12\x1c\n" +
+	"\tdetectors\x18\x03 \x03(\tR\tdetectors\x12?\n" +
+	"\x06labels\x18\x04 \x03(\v2'.watcher.ServerInfoResponse.LabelsEntryR\x06labels\x12%\n" +
+	"\x0euptime_seconds\x18\x05 \x01(\x03R\ruptimeSeconds\x12\x1b\n" +
+	"\tauth_mode\x18\x06 \x01(\tR\bauthMode\x12\x1a\n" +
+	"\bhostname\x18\a \x01(\tR\bhostname\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x01\n" +
+	"\n" +
+	"HostReport\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12:\n" +
+	"\vobservation\x18\x03 \x01(\v2\x18.watcher.ObserveResponseR\vobservation\x12\x1b\n" +
+	"\tlast_seen\x18\x04 \x01(\x03R\blastSeen\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"E\n" +
+	"\x11QueryFleetRequest\x12\x14\n" +
+	"\x05hosts\x18\x01 \x03(\tR\x05hosts\x12\x1a\n" +
+	"\bselector\x18\x02 \x01(\tR\bselector\"?\n" +
+	"\x12QueryFleetResponse\x12)\n" +
+	"\x05hosts\x18\x01 \x03(\v2\x13.watcher.HostReportR\x05hosts\"]\n" +
+	"\vPushRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12:\n" +
+	"\vobservation\x18\x02 \x01(\v2\x18.watcher.ObserveResponseR\vobservation\"*\n" +
+	"\fPushResponse\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\x05R\baccepted\">\n" +
+	"\x0eHistoryRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x18\n" +
+	"\aruntime\x18\x02 \x01(\tR\aruntime\"\xa0\x01\n" +
+	"\rRuntimeChange\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x18\n" +
+	"\aruntime\x18\x02 \x01(\tR\aruntime\x12)\n" +
+	"\x10previous_version\x18\x03 \x01(\tR\x0fpreviousVersion\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\"W\n" +
+	"\x0fHistoryResponse\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x120\n" +
+	"\achanges\x18\x02 \x03(\v2\x16.watcher.RuntimeChangeR\achanges2\xa5\x02\n" +
+	"\x0eWatcherService\x12D\n" +
+	"\x0fObserveRuntimes\x12\x17.watcher.ObserveRequest\x1a\x18.watcher.ObserveResponse\x12B\n" +
+	"\tRotateKey\x12\x19.watcher.RotateKeyRequest\x1a\x1a.watcher.RotateKeyResponse\x12?\n" +
+	"\rWatchRuntimes\x12\x15.watcher.WatchRequest\x1a\x15.watcher.RuntimeEvent0\x01\x12H\n" +
+	"\rGetServerInfo\x12\x1a.watcher.ServerInfoRequest\x1a\x1b.watcher.ServerInfoResponse2\xdd\x01\n" +
+	"\x10CollectorService\x12E\n" +
+	"\n" +
+	"QueryFleet\x12\x1a.watcher.QueryFleetRequest\x1a\x1b.watcher.QueryFleetResponse\x12A\n" +
+	"\x10PushObservations\x12\x14.watcher.PushRequest\x1a\x15.watcher.PushResponse(\x01\x12?\n" +
+	"\n" +
+	"GetHistory\x12\x17.watcher.HistoryRequest\x1a\x18.watcher.HistoryResponseB$Z\"github.com/binaryarc/watcher/protob\x06proto3"
 
 var (
 	file_proto_watcher_proto_rawDescOnce sync.Once
@@ -290,23 +1254,61 @@ func file_proto_watcher_proto_rawDescGZIP() []byte {
 	return file_proto_watcher_proto_rawDescData
 }
 
-var file_proto_watcher_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_watcher_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_watcher_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_proto_watcher_proto_goTypes = []any{
-	(*Runtime)(nil),         // 0: watcher.Runtime
-	(*SystemInfo)(nil),      // 1: watcher.SystemInfo
-	(*ObserveRequest)(nil),  // 2: watcher.ObserveRequest
-	(*ObserveResponse)(nil), // 3: watcher.ObserveResponse
+	(RuntimeEvent_Type)(0),     // 0: watcher.RuntimeEvent.Type
+	(*Runtime)(nil),            // 1: watcher.Runtime
+	(*SystemInfo)(nil),         // 2: watcher.SystemInfo
+	(*ObserveRequest)(nil),     // 3: watcher.ObserveRequest
+	(*ObserveResponse)(nil),    // 4: watcher.ObserveResponse
+	(*RotateKeyRequest)(nil),   // 5: watcher.RotateKeyRequest
+	(*RotateKeyResponse)(nil),  // 6: watcher.RotateKeyResponse
+	(*WatchRequest)(nil),       // 7: watcher.WatchRequest
+	(*RuntimeEvent)(nil),       // 8: watcher.RuntimeEvent
+	(*ServerInfoRequest)(nil),  // 9: watcher.ServerInfoRequest
+	(*ServerInfoResponse)(nil), // 10: watcher.ServerInfoResponse
+	(*HostReport)(nil),         // 11: watcher.HostReport
+	(*QueryFleetRequest)(nil),  // 12: watcher.QueryFleetRequest
+	(*QueryFleetResponse)(nil), // 13: watcher.QueryFleetResponse
+	(*PushRequest)(nil),        // 14: watcher.PushRequest
+	(*PushResponse)(nil),       // 15: watcher.PushResponse
+	(*HistoryRequest)(nil),     // 16: watcher.HistoryRequest
+	(*RuntimeChange)(nil),      // 17: watcher.RuntimeChange
+	(*HistoryResponse)(nil),    // 18: watcher.HistoryResponse
+	nil,                        // 19: watcher.SystemInfo.LabelsEntry
+	nil,                        // 20: watcher.ServerInfoResponse.LabelsEntry
 }
 var file_proto_watcher_proto_depIdxs = []int32{
-	0, // 0: watcher.ObserveResponse.runtimes:type_name -> watcher.Runtime
-	1, // 1: watcher.ObserveResponse.system_info:type_name -> watcher.SystemInfo
-	2, // 2: watcher.WatcherService.ObserveRuntimes:input_type -> watcher.ObserveRequest
-	3, // 3: watcher.WatcherService.ObserveRuntimes:output_type -> watcher.ObserveResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	19, // 0: watcher.SystemInfo.labels:type_name -> watcher.SystemInfo.LabelsEntry
+	1,  // 1: watcher.ObserveResponse.runtimes:type_name -> watcher.Runtime
+	2,  // 2: watcher.ObserveResponse.system_info:type_name -> watcher.SystemInfo
+	0,  // 3: watcher.RuntimeEvent.type:type_name -> watcher.RuntimeEvent.Type
+	1,  // 4: watcher.RuntimeEvent.runtime:type_name -> watcher.Runtime
+	20, // 5: watcher.ServerInfoResponse.labels:type_name -> watcher.ServerInfoResponse.LabelsEntry
+	4,  // 6: watcher.HostReport.observation:type_name -> watcher.ObserveResponse
+	11, // 7: watcher.QueryFleetResponse.hosts:type_name -> watcher.HostReport
+	4,  // 8: watcher.PushRequest.observation:type_name -> watcher.ObserveResponse
+	17, // 9: watcher.HistoryResponse.changes:type_name -> watcher.RuntimeChange
+	3,  // 10: watcher.WatcherService.ObserveRuntimes:input_type -> watcher.ObserveRequest
+	5,  // 11: watcher.WatcherService.RotateKey:input_type -> watcher.RotateKeyRequest
+	7,  // 12: watcher.WatcherService.WatchRuntimes:input_type -> watcher.WatchRequest
+	9,  // 13: watcher.WatcherService.GetServerInfo:input_type -> watcher.ServerInfoRequest
+	12, // 14: watcher.CollectorService.QueryFleet:input_type -> watcher.QueryFleetRequest
+	14, // 15: watcher.CollectorService.PushObservations:input_type -> watcher.PushRequest
+	16, // 16: watcher.CollectorService.GetHistory:input_type -> watcher.HistoryRequest
+	4,  // 17: watcher.WatcherService.ObserveRuntimes:output_type -> watcher.ObserveResponse
+	6,  // 18: watcher.WatcherService.RotateKey:output_type -> watcher.RotateKeyResponse
+	8,  // 19: watcher.WatcherService.WatchRuntimes:output_type -> watcher.RuntimeEvent
+	10, // 20: watcher.WatcherService.GetServerInfo:output_type -> watcher.ServerInfoResponse
+	13, // 21: watcher.CollectorService.QueryFleet:output_type -> watcher.QueryFleetResponse
+	15, // 22: watcher.CollectorService.PushObservations:output_type -> watcher.PushResponse
+	18, // 23: watcher.CollectorService.GetHistory:output_type -> watcher.HistoryResponse
+	17, // [17:24] is the sub-list for method output_type
+	10, // [10:17] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_watcher_proto_init() }
@@ -319,13 +1321,14 @@ func file_proto_watcher_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_watcher_proto_rawDesc), len(file_proto_watcher_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   20,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_watcher_proto_goTypes,
 		DependencyIndexes: file_proto_watcher_proto_depIdxs,
+		EnumInfos:         file_proto_watcher_proto_enumTypes,
 		MessageInfos:      file_proto_watcher_proto_msgTypes,
 	}.Build()
 	File_proto_watcher_proto = out.File
@@ -20,6 +20,9 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	WatcherService_ObserveRuntimes_FullMethodName = "/watcher.WatcherService/ObserveRuntimes"
+	WatcherService_RotateKey_FullMethodName       = "/watcher.WatcherService/RotateKey"
+	WatcherService_WatchRuntimes_FullMethodName   = "/watcher.WatcherService/WatchRuntimes"
+	WatcherService_GetServerInfo_FullMethodName   = "/watcher.WatcherService/GetServerInfo"
 )
 
 // WatcherServiceClient is the client API for WatcherService service.
@@ -27,6 +30,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type WatcherServiceClient interface {
 	ObserveRuntimes(ctx context.Context, in *ObserveRequest, opts ...grpc.CallOption) (*ObserveResponse, error)
+	RotateKey(ctx context.Context, in *RotateKeyRequest, opts ...grpc.CallOption) (*RotateKeyResponse, error)
+	WatchRuntimes(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RuntimeEvent], error)
+	GetServerInfo(ctx context.Context, in *ServerInfoRequest, opts ...grpc.CallOption) (*ServerInfoResponse, error)
 }
 
 type watcherServiceClient struct {
@@ -47,11 +53,53 @@ func (c *watcherServiceClient) ObserveRuntimes(ctx context.Context, in *ObserveR
 	return out, nil
 }
 
+func (c *watcherServiceClient) RotateKey(ctx context.Context, in *RotateKeyRequest, opts ...grpc.CallOption) (*RotateKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RotateKeyResponse)
+	err := c.cc.Invoke(ctx, WatcherService_RotateKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watcherServiceClient) WatchRuntimes(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RuntimeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WatcherService_ServiceDesc.Streams[0], WatcherService_WatchRuntimes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, RuntimeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatcherService_WatchRuntimesClient = grpc.ServerStreamingClient[RuntimeEvent]
+
+func (c *watcherServiceClient) GetServerInfo(ctx context.Context, in *ServerInfoRequest, opts ...grpc.CallOption) (*ServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerInfoResponse)
+	err := c.cc.Invoke(ctx, WatcherService_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WatcherServiceServer is the server API for WatcherService service.
 // All implementations must embed UnimplementedWatcherServiceServer
 // for forward compatibility.
 type WatcherServiceServer interface {
 	ObserveRuntimes(context.Context, *ObserveRequest) (*ObserveResponse, error)
+	RotateKey(context.Context, *RotateKeyRequest) (*RotateKeyResponse, error)
+	WatchRuntimes(*WatchRequest, grpc.ServerStreamingServer[RuntimeEvent]) error
+	GetServerInfo(context.Context, *ServerInfoRequest) (*ServerInfoResponse, error)
 	mustEmbedUnimplementedWatcherServiceServer()
 }
 
@@ -65,6 +113,15 @@ type UnimplementedWatcherServiceServer struct{}
 func (UnimplementedWatcherServiceServer) ObserveRuntimes(context.Context, *ObserveRequest) (*ObserveResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ObserveRuntimes not implemented")
 }
+func (UnimplementedWatcherServiceServer) RotateKey(context.Context, *RotateKeyRequest) (*RotateKeyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RotateKey not implemented")
+}
+func (UnimplementedWatcherServiceServer) WatchRuntimes(*WatchRequest, grpc.ServerStreamingServer[RuntimeEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchRuntimes not implemented")
+}
+func (UnimplementedWatcherServiceServer) GetServerInfo(context.Context, *ServerInfoRequest) (*ServerInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetServerInfo not implemented")
+}
 func (UnimplementedWatcherServiceServer) mustEmbedUnimplementedWatcherServiceServer() {}
 func (UnimplementedWatcherServiceServer) testEmbeddedByValue()                        {}
 
@@ -104,6 +161,53 @@ func _WatcherService_ObserveRuntimes_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WatcherService_RotateKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatcherServiceServer).RotateKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatcherService_RotateKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatcherServiceServer).RotateKey(ctx, req.(*RotateKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatcherService_WatchRuntimes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WatcherServiceServer).WatchRuntimes(m, &grpc.GenericServerStream[WatchRequest, RuntimeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatcherService_WatchRuntimesServer = grpc.ServerStreamingServer[RuntimeEvent]
+
+func _WatcherService_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatcherServiceServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatcherService_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatcherServiceServer).GetServerInfo(ctx, req.(*ServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WatcherService_ServiceDesc is the grpc.ServiceDesc for WatcherService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +219,193 @@ var WatcherService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ObserveRuntimes",
 			Handler:    _WatcherService_ObserveRuntimes_Handler,
 		},
+		{
+			MethodName: "RotateKey",
+			Handler:    _WatcherService_RotateKey_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _WatcherService_GetServerInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRuntimes",
+			Handler:       _WatcherService_WatchRuntimes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/watcher.proto",
+}
+
+const (
+	CollectorService_QueryFleet_FullMethodName       = "/watcher.CollectorService/QueryFleet"
+	CollectorService_PushObservations_FullMethodName = "/watcher.CollectorService/PushObservations"
+	CollectorService_GetHistory_FullMethodName       = "/watcher.CollectorService/GetHistory"
+)
+
+// CollectorServiceClient is the client API for CollectorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CollectorServiceClient interface {
+	QueryFleet(ctx context.Context, in *QueryFleetRequest, opts ...grpc.CallOption) (*QueryFleetResponse, error)
+	PushObservations(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PushRequest, PushResponse], error)
+	GetHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+}
+
+type collectorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCollectorServiceClient(cc grpc.ClientConnInterface) CollectorServiceClient {
+	return &collectorServiceClient{cc}
+}
+
+func (c *collectorServiceClient) QueryFleet(ctx context.Context, in *QueryFleetRequest, opts ...grpc.CallOption) (*QueryFleetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryFleetResponse)
+	err := c.cc.Invoke(ctx, CollectorService_QueryFleet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectorServiceClient) PushObservations(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PushRequest, PushResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectorService_ServiceDesc.Streams[0], CollectorService_PushObservations_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushRequest, PushResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorService_PushObservationsClient = grpc.ClientStreamingClient[PushRequest, PushResponse]
+
+func (c *collectorServiceClient) GetHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, CollectorService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CollectorServiceServer is the server API for CollectorService service.
+// All implementations must embed UnimplementedCollectorServiceServer
+// for forward compatibility.
+type CollectorServiceServer interface {
+	QueryFleet(context.Context, *QueryFleetRequest) (*QueryFleetResponse, error)
+	PushObservations(grpc.ClientStreamingServer[PushRequest, PushResponse]) error
+	GetHistory(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	mustEmbedUnimplementedCollectorServiceServer()
+}
+
+// UnimplementedCollectorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCollectorServiceServer struct{}
+
+func (UnimplementedCollectorServiceServer) QueryFleet(context.Context, *QueryFleetRequest) (*QueryFleetResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryFleet not implemented")
+}
+func (UnimplementedCollectorServiceServer) PushObservations(grpc.ClientStreamingServer[PushRequest, PushResponse]) error {
+	return status.Error(codes.Unimplemented, "method PushObservations not implemented")
+}
+func (UnimplementedCollectorServiceServer) GetHistory(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedCollectorServiceServer) mustEmbedUnimplementedCollectorServiceServer() {}
+func (UnimplementedCollectorServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeCollectorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CollectorServiceServer will
+// result in compilation errors.
+type UnsafeCollectorServiceServer interface {
+	mustEmbedUnimplementedCollectorServiceServer()
+}
+
+func RegisterCollectorServiceServer(s grpc.ServiceRegistrar, srv CollectorServiceServer) {
+	// If the following call panics, it indicates UnimplementedCollectorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CollectorService_ServiceDesc, srv)
+}
+
+func _CollectorService_QueryFleet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryFleetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).QueryFleet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_QueryFleet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).QueryFleet(ctx, req.(*QueryFleetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectorService_PushObservations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CollectorServiceServer).PushObservations(&grpc.GenericServerStream[PushRequest, PushResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectorService_PushObservationsServer = grpc.ClientStreamingServer[PushRequest, PushResponse]
+
+func _CollectorService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).GetHistory(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CollectorService_ServiceDesc is the grpc.ServiceDesc for CollectorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CollectorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "watcher.CollectorService",
+	HandlerType: (*CollectorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QueryFleet",
+			Handler:    _CollectorService_QueryFleet_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _CollectorService_GetHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushObservations",
+			Handler:       _CollectorService_PushObservations_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/watcher.proto",
 }